@@ -4,17 +4,35 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/campus-share/backend/internal/config"
 	"github.com/campus-share/backend/internal/database"
 	"github.com/campus-share/backend/internal/handlers"
+	"github.com/campus-share/backend/internal/mailer"
 	"github.com/campus-share/backend/internal/middleware"
+	"github.com/campus-share/backend/internal/realtime"
+	"github.com/campus-share/backend/internal/scanner"
+	"github.com/campus-share/backend/internal/services"
+	"github.com/campus-share/backend/internal/storage"
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
 	// Parse command line flags
 	migrateFlag := flag.Bool("migrate", false, "Run database migrations")
+	repairVotesFlag := flag.Bool("repair-votes", false, "Remove duplicate forum votes and recompute vote counters")
+	sendDigestsFlag := flag.Bool("send-digests", false, "Send due email digests (run from cron, e.g. hourly)")
+	backfillActivityFlag := flag.Bool("backfill-activity", false, "Record feed activities for content created before the activity feed existed")
+	computeRecommendationsFlag := flag.Bool("compute-recommendations", false, "Recompute resource similarities for recommendations (run from cron, e.g. nightly)")
+	rollupAnalyticsFlag := flag.Bool("rollup-analytics", false, "Roll up daily platform analytics (run from cron, e.g. nightly)")
+	rollupDaysFlag := flag.Int("rollup-days", 1, "Number of days up to and including yesterday (UTC) to roll up with -rollup-analytics")
+	scanUploadsFlag := flag.Bool("scan-uploads", false, "Scan uploads that are waiting to be scanned or whose scan failed")
+	gcStorageFlag := flag.Bool("gc-storage", false, "Report stored files nothing uses any more (run from cron, e.g. daily)")
+	gcDeleteFlag := flag.Bool("gc-delete", false, "Delete the files found with -gc-storage instead of only reporting them")
 	flag.Parse()
 
 	// Load configuration
@@ -38,6 +56,135 @@ func main() {
 		return
 	}
 
+	// Repair forum vote counters if flag is set
+	if *repairVotesFlag {
+		removed, err := services.NewForumService().RepairVoteCounts()
+		if err != nil {
+			log.Fatalf("Failed to repair vote counts: %v", err)
+		}
+		log.Printf("Vote counts repaired (%d duplicate votes removed)", removed)
+		return
+	}
+
+	// Backfill the activity feed if flag is set
+	if *backfillActivityFlag {
+		recorded, err := services.NewActivityService().Backfill()
+		if err != nil {
+			log.Fatalf("Failed to backfill activities: %v", err)
+		}
+		log.Printf("Recorded %d activities", recorded)
+		return
+	}
+
+	// Recompute recommendation similarities if flag is set
+	if *computeRecommendationsFlag {
+		stored, err := services.NewRecommendationService(&cfg.Similar).ComputeSimilarities()
+		if err != nil {
+			log.Fatalf("Failed to compute recommendations: %v", err)
+		}
+		log.Printf("Stored %d resource similarities", stored)
+		return
+	}
+
+	// Roll up daily platform analytics if flag is set
+	if *rollupAnalyticsFlag {
+		analyticsService := services.NewAnalyticsService()
+		yesterday := time.Now().UTC().AddDate(0, 0, -1)
+		for i := *rollupDaysFlag - 1; i >= 0; i-- {
+			day := yesterday.AddDate(0, 0, -i)
+			stored, err := analyticsService.RollupDay(day)
+			if err != nil {
+				log.Fatalf("Failed to roll up analytics for %s: %v", day.Format("2006-01-02"), err)
+			}
+			log.Printf("Rolled up analytics for %s (%d rows)", day.Format("2006-01-02"), stored)
+		}
+		return
+	}
+
+	// Send email digests if flag is set
+	if *sendDigestsFlag {
+		digestService := services.NewDigestService(&cfg.Mail, mailer.New(&cfg.Mail))
+		sent, err := digestService.SendDueDigests(time.Now())
+		if err != nil {
+			log.Fatalf("Failed to send digests: %v", err)
+		}
+		log.Printf("Sent %d email digests", sent)
+		return
+	}
+
+	// Scan files for malware in the background; downloads wait for the scan
+	fileScanner, err := scanner.New(&cfg.Scanner)
+	if err != nil {
+		log.Fatalf("Failed to create malware scanner: %v", err)
+	}
+	s3Storage, err := storage.NewS3Storage(&cfg.AWS)
+	if err != nil {
+		log.Fatalf("Failed to create storage: %v", err)
+	}
+	scanService := services.NewScanService(fileScanner, s3Storage, &cfg.Scanner)
+
+	// Scan pending uploads if flag is set
+	if *scanUploadsFlag {
+		clean, err := scanService.ScanPending()
+		if err != nil {
+			log.Fatalf("Failed to scan uploads: %v", err)
+		}
+		log.Printf("Scanned uploads (%d clean)", clean)
+		return
+	}
+
+	// Collect unused stored files if flag is set
+	if *gcStorageFlag {
+		report, err := services.NewStorageGCService(s3Storage, &cfg.Storage).Collect(*gcDeleteFlag)
+		if err != nil {
+			log.Fatalf("Failed to collect stored files: %v", err)
+		}
+		var unusedBytes int64
+		for _, file := range report.Unused {
+			reason := "orphaned"
+			if file.Expired {
+				reason = "deleted resource"
+			}
+			log.Printf("Unused file %s (%d bytes, %s)", file.Key, file.Size, reason)
+			unusedBytes += file.Size
+		}
+		log.Printf("Scanned %d files: %d unused (%d bytes), %d deleted, %d failed; retention over for %d deleted resources",
+			report.Scanned, len(report.Unused), unusedBytes, report.Deleted, report.Failed, report.PurgedResources)
+		return
+	}
+
+	// Start the event hub that pushes live updates to connected clients
+	hub, err := realtime.NewHub(realtime.NewMemoryBroker(), cfg.Realtime.HistorySize)
+	if err != nil {
+		log.Fatalf("Failed to start event hub: %v", err)
+	}
+	defer hub.Close()
+	realtime.SetDefault(hub)
+
+	// Batch view and download counting; queued events are written on shutdown
+	recorder := services.NewEngagementRecorder(cfg.Engagement.FlushInterval, cfg.Engagement.BatchSize)
+	services.SetEngagementRecorder(recorder)
+
+	// Uploads still waiting from before a restart are queued again
+	scanService.Start(cfg.Scanner.Workers)
+	services.SetScanService(scanService)
+	if queued, err := scanService.EnqueuePending(); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if queued > 0 {
+		log.Printf("Queued %d uploads for malware scanning", queued)
+	}
+
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		scanService.Close()
+		if err := recorder.Close(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		os.Exit(0)
+	}()
+
 	// Set Gin mode
 	if cfg.Server.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
@@ -64,11 +211,20 @@ func main() {
 	commentHandler := handlers.NewCommentHandler()
 	ratingHandler := handlers.NewRatingHandler()
 	bookmarkHandler := handlers.NewBookmarkHandler()
+	collectionHandler := handlers.NewCollectionHandler()
 	reportHandler := handlers.NewReportHandler()
 	adminHandler := handlers.NewAdminHandler()
-	recommendationHandler := handlers.NewRecommendationHandler()
+	recommendationHandler := handlers.NewRecommendationHandler(cfg)
 	followHandler := handlers.NewFollowHandler()
 	forumHandler := handlers.NewForumHandler()
+	notificationHandler := handlers.NewNotificationHandler()
+	streamHandler := handlers.NewStreamHandler(cfg, hub)
+	digestHandler := handlers.NewDigestHandler(cfg)
+	courseHandler := handlers.NewCourseHandler()
+	activityHandler := handlers.NewActivityHandler()
+	trendingHandler := handlers.NewTrendingHandler()
+	analyticsHandler := handlers.NewAnalyticsHandler()
+	quotaHandler := handlers.NewQuotaHandler(cfg)
 
 	// API routes
 	api := router.Group("/api/v1")
@@ -78,6 +234,7 @@ func main() {
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/appeal", authHandler.AppealBan)
 			auth.GET("/me", middleware.AuthMiddleware(cfg), authHandler.GetProfile)
 			auth.PUT("/profile", middleware.AuthMiddleware(cfg), authHandler.UpdateProfile)
 		}
@@ -87,11 +244,30 @@ func main() {
 		{
 			resources.GET("", middleware.OptionalAuthMiddleware(cfg), resourceHandler.ListResources)
 			resources.POST("", middleware.AuthMiddleware(cfg), resourceHandler.CreateResource)
+			resources.GET("/duplicates", middleware.OptionalAuthMiddleware(cfg), resourceHandler.FindDuplicates)
+			resources.GET("/trash", middleware.AuthMiddleware(cfg), resourceHandler.ListTrash)
+			resources.DELETE("/trash/:id", middleware.AuthMiddleware(cfg), resourceHandler.PurgeResource)
 			resources.GET("/:id", middleware.OptionalAuthMiddleware(cfg), resourceHandler.GetResource)
 			resources.PUT("/:id", middleware.AuthMiddleware(cfg), resourceHandler.UpdateResource)
 			resources.DELETE("/:id", middleware.AuthMiddleware(cfg), resourceHandler.DeleteResource)
+			resources.POST("/:id/restore", middleware.AuthMiddleware(cfg), resourceHandler.RestoreResource)
 			resources.GET("/:id/download", middleware.AuthMiddleware(cfg), resourceHandler.DownloadResource)
 
+			// Versions
+			resources.GET("/:id/versions", middleware.OptionalAuthMiddleware(cfg), resourceHandler.ListVersions)
+			resources.POST("/:id/versions", middleware.AuthMiddleware(cfg), resourceHandler.UploadVersion)
+			resources.PUT("/:id/versions/:number/current", middleware.AuthMiddleware(cfg), resourceHandler.SetCurrentVersion)
+			resources.GET("/:id/versions/:number/download", middleware.AuthMiddleware(cfg), resourceHandler.DownloadVersion)
+
+			// Attached files
+			resources.POST("/:id/files", middleware.AuthMiddleware(cfg), resourceHandler.AddFiles)
+			resources.DELETE("/:id/files/:file_id", middleware.AuthMiddleware(cfg), resourceHandler.RemoveFile)
+			resources.GET("/:id/files/:file_id/download", middleware.AuthMiddleware(cfg), resourceHandler.DownloadFile)
+			resources.GET("/:id/bundle", middleware.AuthMiddleware(cfg), resourceHandler.DownloadBundle)
+
+			// Duplicates (moderators only)
+			resources.POST("/:id/merge", middleware.AuthMiddleware(cfg), middleware.AdminMiddleware(), resourceHandler.MergeResource)
+
 			// Comments
 			resources.GET("/:id/comments", commentHandler.ListComments)
 			resources.POST("/:id/comments", middleware.AuthMiddleware(cfg), commentHandler.CreateComment)
@@ -104,7 +280,10 @@ func main() {
 			resources.POST("/:id/report", middleware.AuthMiddleware(cfg), reportHandler.CreateReport)
 
 			// Recommendations
-			resources.GET("/:id/similar", recommendationHandler.GetSimilarResources)
+			resources.GET("/:id/similar", middleware.OptionalAuthMiddleware(cfg), recommendationHandler.GetSimilarResources)
+
+			// Usage analytics for the uploader
+			resources.GET("/:id/analytics", middleware.AuthMiddleware(cfg), analyticsHandler.GetResourceAnalytics)
 		}
 
 		// Comment routes
@@ -121,9 +300,37 @@ func main() {
 		{
 			bookmarks.GET("", bookmarkHandler.ListBookmarks)
 			bookmarks.POST("", bookmarkHandler.CreateBookmark)
+			bookmarks.POST("/bulk", bookmarkHandler.BulkBookmarks)
+			bookmarks.PUT("/:id", bookmarkHandler.UpdateBookmark)
 			bookmarks.DELETE("/:id", bookmarkHandler.DeleteBookmark)
+
+			// Folders
+			bookmarks.GET("/folders", bookmarkHandler.ListFolders)
+			bookmarks.POST("/folders", bookmarkHandler.CreateFolder)
+			bookmarks.PUT("/folders/:id", bookmarkHandler.RenameFolder)
+			bookmarks.DELETE("/folders/:id", bookmarkHandler.DeleteFolder)
 		}
 
+		// Collection routes
+		collections := api.Group("/collections")
+		{
+			collections.GET("", collectionHandler.ListCollections)
+			collections.POST("", middleware.AuthMiddleware(cfg), collectionHandler.CreateCollection)
+			collections.GET("/following", middleware.AuthMiddleware(cfg), collectionHandler.ListFollowedCollections)
+			collections.GET("/:id", middleware.OptionalAuthMiddleware(cfg), collectionHandler.GetCollection)
+			collections.PUT("/:id", middleware.AuthMiddleware(cfg), collectionHandler.UpdateCollection)
+			collections.DELETE("/:id", middleware.AuthMiddleware(cfg), collectionHandler.DeleteCollection)
+			collections.POST("/:id/items", middleware.AuthMiddleware(cfg), collectionHandler.AddItem)
+			collections.PUT("/:id/items", middleware.AuthMiddleware(cfg), collectionHandler.ReorderItems)
+			collections.DELETE("/:id/items/:resource_id", middleware.AuthMiddleware(cfg), collectionHandler.RemoveItem)
+			collections.POST("/:id/follow", middleware.AuthMiddleware(cfg), collectionHandler.FollowCollection)
+			collections.DELETE("/:id/follow", middleware.AuthMiddleware(cfg), collectionHandler.UnfollowCollection)
+			collections.POST("/:id/copy", middleware.AuthMiddleware(cfg), collectionHandler.CopyCollection)
+		}
+
+		// Collections on a user's profile
+		api.GET("/users/:id/collections", middleware.OptionalAuthMiddleware(cfg), collectionHandler.ListUserCollections)
+
 		// Recommendation routes
 		recommendations := api.Group("/recommendations")
 		recommendations.Use(middleware.AuthMiddleware(cfg))
@@ -143,19 +350,83 @@ func main() {
 			follows.GET("/feed", followHandler.GetActivityFeed)
 		}
 
+		// Activity feed
+		api.GET("/feed", middleware.AuthMiddleware(cfg), activityHandler.GetFeed)
+
+		// Trending resources and topics
+		api.GET("/trending", middleware.OptionalAuthMiddleware(cfg), trendingHandler.GetTrending)
+
+		// Notification routes
+		notifications := api.Group("/notifications")
+		notifications.Use(middleware.AuthMiddleware(cfg))
+		{
+			notifications.GET("", notificationHandler.ListNotifications)
+			notifications.GET("/unread-count", notificationHandler.GetUnreadCount)
+			notifications.POST("/:id/read", notificationHandler.MarkRead)
+			notifications.POST("/read-all", notificationHandler.MarkAllRead)
+			notifications.GET("/preferences", notificationHandler.GetPreferences)
+			notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+		}
+
+		// Email digest routes
+		digest := api.Group("/digest")
+		{
+			digest.GET("/preferences", middleware.AuthMiddleware(cfg), digestHandler.GetPreference)
+			digest.PUT("/preferences", middleware.AuthMiddleware(cfg), digestHandler.UpdatePreference)
+			digest.GET("/unsubscribe", digestHandler.Unsubscribe)
+			digest.POST("/unsubscribe", digestHandler.Unsubscribe)
+		}
+
+		// Course routes
+		courses := api.Group("/courses")
+		courses.Use(middleware.AuthMiddleware(cfg))
+		{
+			courses.GET("/watched", courseHandler.ListWatchedCourses)
+			courses.POST("/:id/watch", courseHandler.WatchCourse)
+			courses.DELETE("/:id/watch", courseHandler.UnwatchCourse)
+		}
+
+		// Real-time event stream
+		api.GET("/stream", middleware.StreamAuthMiddleware(cfg), streamHandler.Stream)
+
 		// Forum routes
 		forum := api.Group("/forum")
 		{
 			// Topics
 			forum.GET("/topics", forumHandler.ListTopics)
 			forum.POST("/topics", middleware.AuthMiddleware(cfg), forumHandler.CreateTopic)
-			forum.GET("/topics/:id", forumHandler.GetTopic)
+			forum.GET("/topics/:id", middleware.OptionalAuthMiddleware(cfg), forumHandler.GetTopic)
+			forum.PUT("/topics/:id", middleware.AuthMiddleware(cfg), forumHandler.UpdateTopic)
+			forum.DELETE("/topics/:id", middleware.AuthMiddleware(cfg), forumHandler.DeleteTopic)
 			forum.GET("/topics/:id/replies", forumHandler.ListReplies)
 			forum.POST("/topics/:id/replies", middleware.AuthMiddleware(cfg), forumHandler.CreateReply)
 			forum.POST("/topics/:id/vote", middleware.AuthMiddleware(cfg), forumHandler.VoteOnTopic)
+			forum.DELETE("/topics/:id/vote", middleware.AuthMiddleware(cfg), forumHandler.ClearTopicVote)
+			forum.POST("/topics/:id/accept", middleware.AuthMiddleware(cfg), forumHandler.AcceptAnswer)
+			forum.DELETE("/topics/:id/accept", middleware.AuthMiddleware(cfg), forumHandler.UnacceptAnswer)
 
 			// Replies
+			forum.PUT("/replies/:id", middleware.AuthMiddleware(cfg), forumHandler.UpdateReply)
+			forum.DELETE("/replies/:id", middleware.AuthMiddleware(cfg), forumHandler.DeleteReply)
 			forum.POST("/replies/:id/vote", middleware.AuthMiddleware(cfg), forumHandler.VoteOnReply)
+			forum.DELETE("/replies/:id/vote", middleware.AuthMiddleware(cfg), forumHandler.ClearReplyVote)
+			forum.GET("/replies/:id/revisions", middleware.AuthMiddleware(cfg), middleware.AdminMiddleware(), forumHandler.ListReplyRevisions)
+
+			// Moderation
+			moderation := forum.Group("/topics/:id")
+			moderation.Use(middleware.AuthMiddleware(cfg))
+			moderation.Use(middleware.AdminMiddleware())
+			{
+				moderation.POST("/pin", forumHandler.PinTopic)
+				moderation.POST("/unpin", forumHandler.UnpinTopic)
+				moderation.POST("/lock", forumHandler.LockTopic)
+				moderation.POST("/unlock", forumHandler.UnlockTopic)
+				moderation.POST("/hide", forumHandler.HideTopic)
+				moderation.POST("/restore", forumHandler.RestoreTopic)
+				moderation.PUT("/move", forumHandler.MoveTopic)
+				moderation.POST("/merge", forumHandler.MergeTopic)
+				moderation.GET("/revisions", forumHandler.ListTopicRevisions)
+			}
 		}
 
 		// Admin routes
@@ -171,11 +442,33 @@ func main() {
 			// User management
 			admin.POST("/users/:id/ban", adminHandler.BanUser)
 			admin.POST("/users/:id/unban", adminHandler.UnbanUser)
+			admin.GET("/users/:id/bans", adminHandler.ListUserBans)
+			admin.GET("/users/:id/trash", resourceHandler.ListUserTrash)
+
+			// Ban appeals
+			admin.GET("/appeals", adminHandler.ListAppeals)
+			admin.POST("/appeals/:id/approve", adminHandler.ApproveAppeal)
+			admin.POST("/appeals/:id/reject", adminHandler.RejectAppeal)
+
+			// Course TAs
+			admin.GET("/courses/:id/tas", adminHandler.ListCourseTAs)
+			admin.POST("/courses/:id/tas", adminHandler.AddCourseTA)
+			admin.DELETE("/courses/:id/tas/:user_id", adminHandler.RemoveCourseTA)
 
 			// Analytics
 			admin.GET("/analytics", adminHandler.GetAnalytics)
 			admin.GET("/analytics/popular", adminHandler.GetPopularResources)
 			admin.GET("/analytics/resources/:id", adminHandler.GetResourceStats)
+			admin.GET("/analytics/daily", analyticsHandler.GetPlatformAnalytics)
+
+			// Duplicate uploads
+			admin.GET("/resources/duplicates", resourceHandler.ListDuplicateGroups)
+
+			// Storage quotas
+			admin.GET("/users/:id/storage", quotaHandler.GetUserStorage)
+			admin.PUT("/users/:id/quota", quotaHandler.SetUserQuota)
+			admin.GET("/universities/:id/quotas", quotaHandler.ListUniversityQuotas)
+			admin.PUT("/universities/:id/quotas/:role", quotaHandler.SetUniversityQuota)
 		}
 	}
 
@@ -11,14 +11,21 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server    ServerConfig
-	Database  DatabaseConfig
-	JWT       JWTConfig
-	AWS       AWSConfig
-	OAuth     OAuthConfig
-	CORS      CORSConfig
-	Upload    UploadConfig
-	RateLimit RateLimitConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	JWT        JWTConfig
+	AWS        AWSConfig
+	OAuth      OAuthConfig
+	CORS       CORSConfig
+	Upload     UploadConfig
+	RateLimit  RateLimitConfig
+	Realtime   RealtimeConfig
+	Mail       MailConfig
+	Similar    SimilarConfig
+	Engagement EngagementConfig
+	Quota      QuotaConfig
+	Scanner    ScannerConfig
+	Storage    StorageConfig
 }
 
 // ServerConfig holds server-related configuration
@@ -79,6 +86,64 @@ type RateLimitConfig struct {
 	Window   time.Duration
 }
 
+// RealtimeConfig holds event stream configuration
+type RealtimeConfig struct {
+	HeartbeatInterval time.Duration
+	HistorySize       int // Events kept for clients resuming with Last-Event-ID
+}
+
+// MailConfig holds outgoing email configuration.
+// Without an SMTP host, emails are written to the log.
+type MailConfig struct {
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	AppURL       string // Frontend base URL used for links in emails
+	APIURL       string // Public API base URL used for unsubscribe links
+}
+
+// SimilarConfig holds the weights used to score similar resources.
+// Each signal scores 0 to 1 and is multiplied by its weight.
+type SimilarConfig struct {
+	TagWeight     float64 // Overlap of the resources' tags
+	TextWeight    float64 // Similarity of title and description
+	CatalogWeight float64 // Same course, department or university
+}
+
+// EngagementConfig holds how view and download events are batched
+type EngagementConfig struct {
+	FlushInterval time.Duration
+	BatchSize     int // Events that trigger a flush before the interval is up
+}
+
+// QuotaConfig holds the default storage quota of each role. Universities and
+// individual users can be given other quotas. 0 means unlimited.
+type QuotaConfig struct {
+	StudentMB      int
+	StudentFiles   int
+	ModeratorMB    int
+	ModeratorFiles int
+	AdminMB        int
+	AdminFiles     int
+}
+
+// ScannerConfig holds how uploads are scanned for malware
+type ScannerConfig struct {
+	Driver       string        // "clamd", or "noop" which only catches the EICAR test file
+	ClamdAddress string        // "tcp://host:port" or "unix:///path/to/clamd.sock"
+	Timeout      time.Duration // Per file
+	Workers      int           // Files scanned at the same time
+}
+
+// StorageConfig holds how long files are kept before the storage garbage
+// collector deletes them
+type StorageConfig struct {
+	Retention   time.Duration // How long deleted resources keep their files
+	GracePeriod time.Duration // Files younger than this may still be being uploaded
+}
+
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	// Try to load .env file (ignore error if it doesn't exist)
@@ -127,6 +192,46 @@ func Load() (*Config, error) {
 			Requests: getEnvAsInt("RATE_LIMIT_REQUESTS", 100),
 			Window:   time.Duration(getEnvAsInt("RATE_LIMIT_WINDOW_MINUTES", 15)) * time.Minute,
 		},
+		Realtime: RealtimeConfig{
+			HeartbeatInterval: time.Duration(getEnvAsInt("STREAM_HEARTBEAT_SECONDS", 25)) * time.Second,
+			HistorySize:       getEnvAsInt("STREAM_HISTORY_SIZE", 1000),
+		},
+		Mail: MailConfig{
+			SMTPHost:     getEnv("SMTP_HOST", ""),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			From:         getEnv("MAIL_FROM", "Campus Share <no-reply@campus-share.local>"),
+			AppURL:       getEnv("APP_URL", "http://localhost:3000"),
+			APIURL:       getEnv("API_URL", "http://localhost:8080"),
+		},
+		Similar: SimilarConfig{
+			TagWeight:     getEnvAsFloat("SIMILAR_TAG_WEIGHT", 0.4),
+			TextWeight:    getEnvAsFloat("SIMILAR_TEXT_WEIGHT", 0.35),
+			CatalogWeight: getEnvAsFloat("SIMILAR_CATALOG_WEIGHT", 0.25),
+		},
+		Engagement: EngagementConfig{
+			FlushInterval: time.Duration(getEnvAsInt("ENGAGEMENT_FLUSH_SECONDS", 10)) * time.Second,
+			BatchSize:     getEnvAsInt("ENGAGEMENT_BATCH_SIZE", 500),
+		},
+		Quota: QuotaConfig{
+			StudentMB:      getEnvAsInt("QUOTA_STUDENT_MB", 2048),
+			StudentFiles:   getEnvAsInt("QUOTA_STUDENT_FILES", 500),
+			ModeratorMB:    getEnvAsInt("QUOTA_MODERATOR_MB", 10240),
+			ModeratorFiles: getEnvAsInt("QUOTA_MODERATOR_FILES", 2000),
+			AdminMB:        getEnvAsInt("QUOTA_ADMIN_MB", 0),
+			AdminFiles:     getEnvAsInt("QUOTA_ADMIN_FILES", 0),
+		},
+		Scanner: ScannerConfig{
+			Driver:       getEnv("SCANNER_DRIVER", "noop"),
+			ClamdAddress: getEnv("CLAMD_ADDRESS", "tcp://localhost:3310"),
+			Timeout:      time.Duration(getEnvAsInt("SCANNER_TIMEOUT_SECONDS", 120)) * time.Second,
+			Workers:      getEnvAsInt("SCANNER_WORKERS", 2),
+		},
+		Storage: StorageConfig{
+			Retention:   time.Duration(getEnvAsInt("STORAGE_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			GracePeriod: time.Duration(getEnvAsInt("STORAGE_GC_GRACE_HOURS", 24)) * time.Hour,
+		},
 	}
 
 	// Validate required configuration
@@ -185,6 +290,14 @@ func getEnvAsInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+func getEnvAsFloat(key string, defaultValue float64) float64 {
+	valueStr := getEnv(key, "")
+	if value, err := strconv.ParseFloat(valueStr, 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
 func getEnvAsSlice(key string, defaultValue []string) []string {
 	valueStr := getEnv(key, "")
 	if valueStr == "" {
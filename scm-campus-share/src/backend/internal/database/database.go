@@ -29,6 +29,8 @@ func Connect(cfg *config.Config) error {
 
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
 		Logger: logger.Default.LogMode(logLevel),
+		// Report unique violations as gorm.ErrDuplicatedKey
+		TranslateError: true,
 	})
 
 	if err != nil {
@@ -47,15 +49,35 @@ func Migrate() error {
 
 	log.Println("Running database migrations...")
 
+	// Duplicate votes would block the unique vote index from being created
+	if DB.Migrator().HasTable(&models.ForumVote{}) {
+		removed, err := DeduplicateForumVotes(DB)
+		if err != nil {
+			return fmt.Errorf("failed to deduplicate forum votes: %w", err)
+		}
+		if removed > 0 {
+			log.Printf("Removed %d duplicate forum votes", removed)
+		}
+	}
+
 	err := DB.AutoMigrate(
 		&models.User{},
 		&models.University{},
 		&models.Department{},
 		&models.Course{},
+		&models.CourseTA{},
 		&models.Resource{},
+		&models.ResourceVersion{},
+		&models.ResourceFile{},
+		&models.UniversityQuota{},
+		&models.FileScan{},
 		&models.Comment{},
 		&models.Rating{},
+		&models.BookmarkFolder{},
 		&models.Bookmark{},
+		&models.Collection{},
+		&models.CollectionItem{},
+		&models.CollectionFollow{},
 		&models.Report{},
 		&models.Tag{},
 		&models.ResourceTag{},
@@ -63,16 +85,70 @@ func Migrate() error {
 		&models.ForumTopic{},
 		&models.ForumReply{},
 		&models.ForumVote{},
+		&models.ForumRevision{},
+		&models.UserBan{},
+		&models.BanAppeal{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.CourseWatch{},
+		&models.DigestPreference{},
+		&models.Activity{},
+		&models.ResourceDownload{},
+		&models.ResourceSimilarity{},
+		&models.EngagementEvent{},
+		&models.DailyMetric{},
 	)
 
 	if err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 
+	added, err := BackfillFirstVersions(DB)
+	if err != nil {
+		return fmt.Errorf("failed to backfill resource versions: %w", err)
+	}
+	if added > 0 {
+		log.Printf("Recorded the files of %d resources as their first version", added)
+	}
+
 	log.Println("Database migrations completed successfully")
 	return nil
 }
 
+// DeduplicateForumVotes removes all but the newest vote per user and topic or reply.
+// Vote counters should be recomputed afterwards.
+func DeduplicateForumVotes(db *gorm.DB) (int64, error) {
+	result := db.Exec(`
+		DELETE FROM forum_votes a
+		USING forum_votes b
+		WHERE a.user_id = b.user_id
+		  AND a.votable_type = b.votable_type
+		  AND a.votable_id = b.votable_id
+		  AND (a.created_at, a.id) < (b.created_at, b.id)`)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+// BackfillFirstVersions records the file of every resource uploaded before
+// versions were kept as its version 1
+func BackfillFirstVersions(db *gorm.DB) (int64, error) {
+	result := db.Exec(`
+		INSERT INTO resource_versions
+			(id, resource_id, number, user_id, file_name, file_size, file_type, s3_key, content_hash, created_at)
+		SELECT gen_random_uuid(), r.id, 1, r.user_id, r.file_name, r.file_size, r.file_type, r.s3_key, r.content_hash, r.created_at
+		FROM resources r
+		WHERE NOT EXISTS (SELECT 1 FROM resource_versions v WHERE v.resource_id = r.id)
+		ON CONFLICT DO NOTHING`)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
 // Close closes the database connection
 func Close() error {
 	if DB == nil {
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/services"
+)
+
+// ActivityHandler handles activity feed HTTP requests
+type ActivityHandler struct {
+	activityService *services.ActivityService
+}
+
+// NewActivityHandler creates a new activity handler
+func NewActivityHandler() *ActivityHandler {
+	return &ActivityHandler{
+		activityService: services.NewActivityService(),
+	}
+}
+
+// GetFeed handles getting the current user's activity feed
+func (h *ActivityHandler) GetFeed(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	req := services.FeedRequest{
+		Cursor: c.Query("cursor"),
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = l
+		}
+	}
+	if types := c.Query("types"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			req.Types = append(req.Types, models.ActivityType(strings.TrimSpace(t)))
+		}
+	}
+
+	items, nextCursor, err := h.activityService.GetFeed(userIDUUID, req)
+	if err != nil {
+		if err == services.ErrInvalidCursor || err == services.ErrInvalidActivityType {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"activities":  items,
+		"next_cursor": nextCursor,
+	})
+}
@@ -14,12 +14,16 @@ import (
 // AdminHandler handles admin-related HTTP requests
 type AdminHandler struct {
 	reportService *services.ReportService
+	banService    *services.BanService
+	courseService *services.CourseService
 }
 
 // NewAdminHandler creates a new admin handler
 func NewAdminHandler() *AdminHandler {
 	return &AdminHandler{
 		reportService: services.NewReportService(),
+		banService:    services.NewBanService(),
+		courseService: services.NewCourseService(),
 	}
 }
 
@@ -137,58 +141,265 @@ func (h *AdminHandler) RejectReport(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"report": report})
 }
 
-// BanUser handles banning a user
+// BanUser handles suspending a user, optionally for a limited duration
 func (h *AdminHandler) BanUser(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adminIDUUID, ok := adminID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
 	userID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
 		return
 	}
 
-	var user models.User
-	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	var req services.BanUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	user.IsBanned = true
-	if err := database.DB.Save(&user).Error; err != nil {
+	ban, err := h.banService.BanUser(userID, adminIDUUID, req)
+	if err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrInvalidBanDuration {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to ban user"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "user banned successfully",
-		"user":    user,
+		"ban":     ban,
 	})
 }
 
 // UnbanUser handles unbanning a user
 func (h *AdminHandler) UnbanUser(c *gin.Context) {
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adminIDUUID, ok := adminID.(uuid.UUID)
+	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
 		return
 	}
 
-	var user models.User
-	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
 		return
 	}
 
-	user.IsBanned = false
-	if err := database.DB.Save(&user).Error; err != nil {
+	user, err := h.banService.UnbanUser(userID, adminIDUUID)
+	if err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unban user"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "user unbanned successfully",
-		"user":    user,
+		"user":    user.Account(),
+	})
+}
+
+// ListUserBans handles getting the ban history of a user
+func (h *AdminHandler) ListUserBans(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	bans, err := h.banService.ListUserBans(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bans": bans})
+}
+
+// ListAppeals handles listing ban appeals
+func (h *AdminHandler) ListAppeals(c *gin.Context) {
+	var req services.ListAppealsRequest
+
+	// Parse query parameters
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			req.Page = p
+		}
+	}
+	if pageSize := c.Query("page_size"); pageSize != "" {
+		if ps, err := strconv.Atoi(pageSize); err == nil {
+			req.PageSize = ps
+		}
+	}
+	if status := c.Query("status"); status != "" {
+		req.Status = models.AppealStatus(status)
+	}
+
+	appeals, total, err := h.banService.ListAppeals(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"appeals":   appeals,
+		"total":     total,
+		"page":      req.Page,
+		"page_size": req.PageSize,
 	})
 }
 
+// ApproveAppeal handles approving a ban appeal, which lifts the ban
+func (h *AdminHandler) ApproveAppeal(c *gin.Context) {
+	h.reviewAppeal(c, h.banService.ApproveAppeal)
+}
+
+// RejectAppeal handles rejecting a ban appeal
+func (h *AdminHandler) RejectAppeal(c *gin.Context) {
+	h.reviewAppeal(c, h.banService.RejectAppeal)
+}
+
+func (h *AdminHandler) reviewAppeal(c *gin.Context, review func(appealID, adminID uuid.UUID, req services.ReviewAppealRequest) (*models.BanAppeal, error)) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adminIDUUID, ok := adminID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	appealID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appeal id"})
+		return
+	}
+
+	var req services.ReviewAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appeal, err := review(appealID, adminIDUUID, req)
+	if err != nil {
+		if err == services.ErrAppealNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrAppealAlreadyHandled {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"appeal": appeal})
+}
+
+// ListCourseTAs handles listing the teaching assistants of a course
+func (h *AdminHandler) ListCourseTAs(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course id"})
+		return
+	}
+
+	tas, err := h.courseService.ListTAs(courseID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tas": tas})
+}
+
+// AddCourseTA handles making a user a teaching assistant for a course
+func (h *AdminHandler) AddCourseTA(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course id"})
+		return
+	}
+
+	var req struct {
+		UserID uuid.UUID `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ta, err := h.courseService.AddTA(courseID, req.UserID)
+	if err != nil {
+		switch err {
+		case services.ErrCourseNotFound, services.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrAlreadyCourseTA:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"ta": ta})
+}
+
+// RemoveCourseTA handles removing a teaching assistant from a course
+func (h *AdminHandler) RemoveCourseTA(c *gin.Context) {
+	courseID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course id"})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	if err := h.courseService.RemoveTA(courseID, userID); err != nil {
+		if err == services.ErrNotCourseTA {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "TA removed successfully"})
+}
+
 // GetAnalytics handles getting platform analytics
 func (h *AdminHandler) GetAnalytics(c *gin.Context) {
 	var stats struct {
@@ -232,15 +443,21 @@ func (h *AdminHandler) GetPopularResources(c *gin.Context) {
 		}
 	}
 
-	var resources []models.Resource
-	database.DB.
+	query := database.DB.
 		Preload("User").
 		Preload("University").
 		Preload("Course").
-		Where("is_approved = ?", true).
-		Order("download_count DESC, view_count DESC").
-		Limit(limit).
-		Find(&resources)
+		Where("is_approved = ?", true)
+
+	// "trending" favours recent engagement over all-time totals
+	if c.Query("sort_by") == "trending" {
+		query = query.Scopes(services.OrderByTrending("resource", "resources"))
+	} else {
+		query = query.Order("download_count DESC, view_count DESC")
+	}
+
+	var resources []models.Resource
+	query.Limit(limit).Find(&resources)
 
 	c.JSON(http.StatusOK, gin.H{"resources": resources})
 }
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/services"
+)
+
+// AnalyticsHandler handles analytics HTTP requests
+type AnalyticsHandler struct {
+	analyticsService *services.AnalyticsService
+}
+
+// NewAnalyticsHandler creates a new analytics handler
+func NewAnalyticsHandler() *AnalyticsHandler {
+	return &AnalyticsHandler{
+		analyticsService: services.NewAnalyticsService(),
+	}
+}
+
+// GetResourceAnalytics handles getting usage analytics of a resource for its owner
+func (h *AnalyticsHandler) GetResourceAnalytics(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	dateRange, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	analytics, err := h.analyticsService.GetResourceAnalytics(resourceID, userIDUUID, isModerator, dateRange)
+	if err != nil {
+		switch err {
+		case services.ErrResourceNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case services.ErrInvalidDateRange:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"analytics": analytics})
+}
+
+// GetPlatformAnalytics handles getting the daily platform metrics for admins,
+// as JSON or, with format=csv, as a CSV download
+func (h *AnalyticsHandler) GetPlatformAnalytics(c *gin.Context) {
+	dateRange, ok := parseDateRange(c)
+	if !ok {
+		return
+	}
+
+	var universityID *uuid.UUID
+	if uid := c.Query("university_id"); uid != "" {
+		parsed, err := uuid.Parse(uid)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid university id"})
+			return
+		}
+		universityID = &parsed
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be json or csv"})
+		return
+	}
+
+	series, err := h.analyticsService.GetPlatformAnalytics(dateRange, universityID)
+	if err != nil {
+		if err == services.ErrInvalidDateRange {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if format == "csv" {
+		writePlatformCSV(c, series)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"days": series})
+}
+
+// writePlatformCSV writes the daily platform metrics as a CSV attachment
+func writePlatformCSV(c *gin.Context, series []services.PlatformDay) {
+	filename := "platform-analytics.csv"
+	if len(series) > 0 {
+		filename = fmt.Sprintf("platform-analytics-%s-%s.csv", series[0].Date, series[len(series)-1].Date)
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"date", "signups", "uploads", "views", "downloads",
+		"daily_active_users", "weekly_active_users", "monthly_active_users",
+		"reports_opened", "reports_resolved", "avg_resolution_hours",
+	})
+	for _, day := range series {
+		w.Write([]string{
+			day.Date,
+			strconv.FormatInt(day.Signups, 10),
+			strconv.FormatInt(day.Uploads, 10),
+			strconv.FormatInt(day.Views, 10),
+			strconv.FormatInt(day.Downloads, 10),
+			strconv.FormatInt(day.DailyActiveUsers, 10),
+			strconv.FormatInt(day.WeeklyActiveUsers, 10),
+			strconv.FormatInt(day.MonthlyActiveUsers, 10),
+			strconv.FormatInt(day.ReportsOpened, 10),
+			strconv.FormatInt(day.ReportsResolved, 10),
+			strconv.FormatFloat(day.AvgResolutionHours, 'f', 2, 64),
+		})
+	}
+	w.Flush()
+}
+
+// parseDateRange reads the from and to query parameters (YYYY-MM-DD). It writes
+// an error response and returns false when either is malformed.
+func parseDateRange(c *gin.Context) (services.DateRange, bool) {
+	var dateRange services.DateRange
+
+	if from := c.Query("from"); from != "" {
+		t, err := time.Parse("2006-01-02", from)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date, expected YYYY-MM-DD"})
+			return dateRange, false
+		}
+		dateRange.From = t
+	}
+
+	if to := c.Query("to"); to != "" {
+		t, err := time.Parse("2006-01-02", to)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date, expected YYYY-MM-DD"})
+			return dateRange, false
+		}
+		dateRange.To = t
+	}
+
+	return dateRange, true
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -11,15 +12,19 @@ import (
 
 // AuthHandler handles authentication-related HTTP requests
 type AuthHandler struct {
-	authService *services.AuthService
-	config      *config.Config
+	authService  *services.AuthService
+	banService   *services.BanService
+	quotaService *services.QuotaService
+	config       *config.Config
 }
 
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(cfg *config.Config) *AuthHandler {
 	return &AuthHandler{
-		authService: services.NewAuthService(),
-		config:      cfg,
+		authService:  services.NewAuthService(),
+		banService:   services.NewBanService(),
+		quotaService: services.NewQuotaService(&cfg.Quota),
+		config:       cfg,
 	}
 }
 
@@ -57,10 +62,24 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	user, token, err := h.authService.Login(req, h.config.JWT.Secret, h.config.JWT.ExpirationHours)
 	if err != nil {
+		var banErr *services.BannedError
+		if errors.As(err, &banErr) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":        banErr.Error(),
+				"ban_reason":   banErr.Reason,
+				"banned_until": banErr.Until,
+				"can_appeal":   banErr.CanAppeal,
+			})
+			return
+		}
 		if err == services.ErrInvalidCredentials {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			return
 		}
+		if err == services.ErrAccountInactive {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -91,7 +110,13 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"user": user})
+	storage, err := h.quotaService.GetUsage(userIDUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user.Account(), "storage": storage})
 }
 
 // UpdateProfile handles updating user profile
@@ -123,3 +148,33 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"user": user})
 }
 
+// AppealBan handles a banned user's appeal against their suspension
+func (h *AuthHandler) AppealBan(c *gin.Context) {
+	var req services.CreateAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.authService.VerifyCredentials(req.Email, req.Password)
+	if err != nil {
+		if err == services.ErrInvalidCredentials {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	appeal, err := h.banService.CreateAppeal(user.ID, req.Message)
+	if err != nil {
+		if err == services.ErrBanNotFound || err == services.ErrAppealExists {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"appeal": appeal})
+}
@@ -35,17 +35,15 @@ func (h *BookmarkHandler) CreateBookmark(c *gin.Context) {
 		return
 	}
 
-	var req struct {
-		ResourceID uuid.UUID `json:"resource_id" binding:"required"`
-	}
+	var req services.BookmarkRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	bookmark, err := h.bookmarkService.CreateBookmark(req.ResourceID, userIDUUID)
+	bookmark, err := h.bookmarkService.CreateBookmark(userIDUUID, req)
 	if err != nil {
-		if err == services.ErrResourceNotFound {
+		if err == services.ErrResourceNotFound || err == services.ErrFolderNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
@@ -84,7 +82,29 @@ func (h *BookmarkHandler) ListBookmarks(c *gin.Context) {
 		}
 	}
 
-	bookmarks, total, err := h.bookmarkService.ListBookmarks(userIDUUID, page, pageSize)
+	req := services.ListBookmarksRequest{
+		Type:     c.Query("type"),
+		Search:   c.Query("search"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+	if folderID := c.Query("folder_id"); folderID == "unfiled" {
+		req.Unfiled = true
+	} else if folderID != "" {
+		id, err := uuid.Parse(folderID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid folder id"})
+			return
+		}
+		req.FolderID = &id
+	}
+	if courseID := c.Query("course_id"); courseID != "" {
+		if id, err := uuid.Parse(courseID); err == nil {
+			req.CourseID = &id
+		}
+	}
+
+	bookmarks, total, err := h.bookmarkService.ListBookmarks(userIDUUID, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -119,6 +139,10 @@ func (h *BookmarkHandler) DeleteBookmark(c *gin.Context) {
 	}
 
 	if err := h.bookmarkService.DeleteBookmark(bookmarkID, userIDUUID); err != nil {
+		if err == services.ErrBookmarkNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		if err == services.ErrUnauthorized {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 			return
@@ -130,3 +154,220 @@ func (h *BookmarkHandler) DeleteBookmark(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "bookmark deleted successfully"})
 }
 
+// UpdateBookmark handles changing the note and folder of a bookmark
+func (h *BookmarkHandler) UpdateBookmark(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	bookmarkID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid bookmark id"})
+		return
+	}
+
+	var req services.UpdateBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookmark, err := h.bookmarkService.UpdateBookmark(bookmarkID, userIDUUID, req)
+	if err != nil {
+		switch err {
+		case services.ErrBookmarkNotFound, services.ErrFolderNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bookmark": bookmark})
+}
+
+// BulkBookmarks handles adding, removing or moving many bookmarks at once
+func (h *BookmarkHandler) BulkBookmarks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req services.BulkBookmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	changed, err := h.bookmarkService.BulkBookmarks(userIDUUID, req)
+	if err != nil {
+		switch err {
+		case services.ErrInvalidBulkAction, services.ErrNoBulkResources, services.ErrTooManyBookmarks:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case services.ErrFolderNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"action":  req.Action,
+		"changed": changed,
+	})
+}
+
+// ListFolders handles listing the user's bookmark folders
+func (h *BookmarkHandler) ListFolders(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	folders, unfiled, err := h.bookmarkService.ListFolders(userIDUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"folders":       folders,
+		"unfiled_count": unfiled,
+	})
+}
+
+// CreateFolder handles creating a bookmark folder
+func (h *BookmarkHandler) CreateFolder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	folder, err := h.bookmarkService.CreateFolder(userIDUUID, req.Name)
+	if err != nil {
+		h.respondFolderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"folder": folder})
+}
+
+// RenameFolder handles renaming a bookmark folder
+func (h *BookmarkHandler) RenameFolder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	folderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid folder id"})
+		return
+	}
+
+	var req struct {
+		Name string `json:"name" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	folder, err := h.bookmarkService.RenameFolder(folderID, userIDUUID, req.Name)
+	if err != nil {
+		h.respondFolderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"folder": folder})
+}
+
+// DeleteFolder handles deleting a bookmark folder; its bookmarks are kept
+func (h *BookmarkHandler) DeleteFolder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	folderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid folder id"})
+		return
+	}
+
+	if err := h.bookmarkService.DeleteFolder(folderID, userIDUUID); err != nil {
+		h.respondFolderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "folder deleted successfully"})
+}
+
+// respondFolderError maps bookmark folder errors to HTTP responses
+func (h *BookmarkHandler) respondFolderError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrFolderNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case services.ErrFolderNameRequired:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case services.ErrFolderNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
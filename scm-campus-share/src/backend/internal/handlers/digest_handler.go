@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/config"
+	"github.com/campus-share/backend/internal/mailer"
+	"github.com/campus-share/backend/internal/services"
+)
+
+// DigestHandler handles email digest HTTP requests
+type DigestHandler struct {
+	digestService *services.DigestService
+}
+
+// NewDigestHandler creates a new digest handler
+func NewDigestHandler(cfg *config.Config) *DigestHandler {
+	return &DigestHandler{
+		digestService: services.NewDigestService(&cfg.Mail, mailer.New(&cfg.Mail)),
+	}
+}
+
+// GetPreference handles getting the current user's digest preference
+func (h *DigestHandler) GetPreference(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	preference, err := h.digestService.GetPreference(userIDUUID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preference": preference})
+}
+
+// UpdatePreference handles changing how often the current user receives the digest
+func (h *DigestHandler) UpdatePreference(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req services.UpdateDigestPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preference, err := h.digestService.UpdatePreference(userIDUUID, req)
+	if err != nil {
+		if err == services.ErrInvalidDigestFrequency {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preference": preference})
+}
+
+// Unsubscribe handles unsubscribe links from digest emails; no login is required
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	if err := h.digestService.Unsubscribe(c.Query("token")); err != nil {
+		if err == services.ErrInvalidUnsubscribeToken {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "unsubscribed from email digest"})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/models"
 	"github.com/campus-share/backend/internal/services"
 )
 
@@ -43,6 +44,10 @@ func (h *ForumHandler) CreateTopic(c *gin.Context) {
 
 	topic, err := h.forumService.CreateTopic(userIDUUID, req)
 	if err != nil {
+		if err == services.ErrInvalidTopicType {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -58,7 +63,7 @@ func (h *ForumHandler) GetTopic(c *gin.Context) {
 		return
 	}
 
-	topic, err := h.forumService.GetTopicByID(topicID)
+	topic, err := h.forumService.ViewTopic(topicID, viewerFromContext(c))
 	if err != nil {
 		if err == services.ErrTopicNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -68,6 +73,13 @@ func (h *ForumHandler) GetTopic(c *gin.Context) {
 		return
 	}
 
+	// Hidden topics are only visible to moderators
+	role, _ := c.Get("user_role")
+	if !topic.IsApproved && role != "admin" && role != "moderator" {
+		c.JSON(http.StatusNotFound, gin.H{"error": services.ErrTopicNotFound.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"topic": topic})
 }
 
@@ -87,6 +99,8 @@ func (h *ForumHandler) ListTopics(c *gin.Context) {
 		}
 	}
 	req.Search = c.Query("search")
+	req.Type = c.Query("type")
+	req.Status = c.Query("status")
 	req.SortBy = c.Query("sort_by")
 
 	if courseID := c.Query("course_id"); courseID != "" {
@@ -251,3 +265,453 @@ func (h *ForumHandler) VoteOnReply(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "vote recorded successfully"})
 }
 
+// ClearTopicVote handles removing the current user's vote on a topic
+func (h *ForumHandler) ClearTopicVote(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	topicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic id"})
+		return
+	}
+
+	if err := h.forumService.ClearTopicVote(topicID, userIDUUID); err != nil {
+		if err == services.ErrTopicNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "vote cleared successfully"})
+}
+
+// ClearReplyVote handles removing the current user's vote on a reply
+func (h *ForumHandler) ClearReplyVote(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	replyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reply id"})
+		return
+	}
+
+	if err := h.forumService.ClearReplyVote(replyID, userIDUUID); err != nil {
+		if err == services.ErrReplyNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "vote cleared successfully"})
+}
+
+// PinTopic handles pinning a topic (moderators only)
+func (h *ForumHandler) PinTopic(c *gin.Context) {
+	h.moderateTopic(c, func(topicID uuid.UUID) (*models.ForumTopic, error) {
+		return h.forumService.SetTopicPinned(topicID, true)
+	})
+}
+
+// UnpinTopic handles unpinning a topic (moderators only)
+func (h *ForumHandler) UnpinTopic(c *gin.Context) {
+	h.moderateTopic(c, func(topicID uuid.UUID) (*models.ForumTopic, error) {
+		return h.forumService.SetTopicPinned(topicID, false)
+	})
+}
+
+// LockTopic handles locking a topic against new replies (moderators only)
+func (h *ForumHandler) LockTopic(c *gin.Context) {
+	h.moderateTopic(c, func(topicID uuid.UUID) (*models.ForumTopic, error) {
+		return h.forumService.SetTopicLocked(topicID, true)
+	})
+}
+
+// UnlockTopic handles unlocking a topic (moderators only)
+func (h *ForumHandler) UnlockTopic(c *gin.Context) {
+	h.moderateTopic(c, func(topicID uuid.UUID) (*models.ForumTopic, error) {
+		return h.forumService.SetTopicLocked(topicID, false)
+	})
+}
+
+// HideTopic handles hiding a topic from listings (moderators only)
+func (h *ForumHandler) HideTopic(c *gin.Context) {
+	h.moderateTopic(c, func(topicID uuid.UUID) (*models.ForumTopic, error) {
+		return h.forumService.SetTopicHidden(topicID, true)
+	})
+}
+
+// RestoreTopic handles restoring a hidden topic (moderators only)
+func (h *ForumHandler) RestoreTopic(c *gin.Context) {
+	h.moderateTopic(c, func(topicID uuid.UUID) (*models.ForumTopic, error) {
+		return h.forumService.SetTopicHidden(topicID, false)
+	})
+}
+
+// moderateTopic parses the topic ID and applies a moderation action to it
+func (h *ForumHandler) moderateTopic(c *gin.Context, action func(topicID uuid.UUID) (*models.ForumTopic, error)) {
+	topicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic id"})
+		return
+	}
+
+	topic, err := action(topicID)
+	if err != nil {
+		if err == services.ErrTopicNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+// MoveTopic handles moving a topic to another course, department or university (moderators only)
+func (h *ForumHandler) MoveTopic(c *gin.Context) {
+	topicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic id"})
+		return
+	}
+
+	var req services.MoveTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	topic, err := h.forumService.MoveTopic(topicID, req)
+	if err != nil {
+		switch err {
+		case services.ErrTopicNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrMoveTargetRequired, services.ErrCourseNotFound,
+			services.ErrDepartmentNotFound, services.ErrUniversityNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+// MergeTopic handles merging a duplicate topic into another topic (moderators only)
+func (h *ForumHandler) MergeTopic(c *gin.Context) {
+	topicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic id"})
+		return
+	}
+
+	var req services.MergeTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	topic, err := h.forumService.MergeTopics(topicID, req.TargetTopicID)
+	if err != nil {
+		switch err {
+		case services.ErrTopicNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrCannotMergeSelf:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+// UpdateTopic handles editing a topic (author or moderator)
+func (h *ForumHandler) UpdateTopic(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	topicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic id"})
+		return
+	}
+
+	var req services.UpdateTopicRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	topic, err := h.forumService.UpdateTopic(topicID, userIDUUID, isModerator, req)
+	if err != nil {
+		h.respondEditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+// DeleteTopic handles deleting a topic (author or moderator)
+func (h *ForumHandler) DeleteTopic(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	topicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic id"})
+		return
+	}
+
+	if err := h.forumService.DeleteTopic(topicID, userIDUUID, isModerator); err != nil {
+		h.respondEditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "topic deleted successfully"})
+}
+
+// UpdateReply handles editing a reply (author or moderator)
+func (h *ForumHandler) UpdateReply(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	replyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reply id"})
+		return
+	}
+
+	var req services.UpdateReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reply, err := h.forumService.UpdateReply(replyID, userIDUUID, isModerator, req)
+	if err != nil {
+		h.respondEditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reply": reply})
+}
+
+// DeleteReply handles deleting a reply (author or moderator)
+func (h *ForumHandler) DeleteReply(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	replyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid reply id"})
+		return
+	}
+
+	if err := h.forumService.DeleteReply(replyID, userIDUUID, isModerator); err != nil {
+		h.respondEditError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "reply deleted successfully"})
+}
+
+// respondEditError maps edit and delete errors to HTTP responses
+func (h *ForumHandler) respondEditError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrTopicNotFound, services.ErrReplyNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case services.ErrUnauthorized:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case services.ErrTopicLocked, services.ErrPostDeleted, services.ErrEmptyEdit:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// ListTopicRevisions handles listing the edit history of a topic (moderators only)
+func (h *ForumHandler) ListTopicRevisions(c *gin.Context) {
+	h.listRevisions(c, "topic")
+}
+
+// ListReplyRevisions handles listing the edit history of a reply (moderators only)
+func (h *ForumHandler) ListReplyRevisions(c *gin.Context) {
+	h.listRevisions(c, "reply")
+}
+
+func (h *ForumHandler) listRevisions(c *gin.Context, revisableType string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + revisableType + " id"})
+		return
+	}
+
+	revisions, err := h.forumService.ListRevisions(revisableType, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+// AcceptAnswer handles accepting a reply as the answer to a question topic
+// (topic author, course TA or moderator)
+func (h *ForumHandler) AcceptAnswer(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	topicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic id"})
+		return
+	}
+
+	var req services.AcceptAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	topic, err := h.forumService.AcceptAnswer(topicID, req.ReplyID, userIDUUID, isModerator)
+	if err != nil {
+		h.respondAnswerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+// UnacceptAnswer handles removing the accepted answer from a question topic
+func (h *ForumHandler) UnacceptAnswer(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	topicID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid topic id"})
+		return
+	}
+
+	topic, err := h.forumService.UnacceptAnswer(topicID, userIDUUID, isModerator)
+	if err != nil {
+		h.respondAnswerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"topic": topic})
+}
+
+// respondAnswerError maps accepted-answer errors to HTTP responses
+func (h *ForumHandler) respondAnswerError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrTopicNotFound, services.ErrReplyNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case services.ErrUnauthorized:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case services.ErrNotAQuestion, services.ErrReplyNotInTopic, services.ErrNoAcceptedAnswer, services.ErrPostDeleted:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/config"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/services"
+)
+
+// QuotaHandler handles storage quota HTTP requests
+type QuotaHandler struct {
+	quotaService *services.QuotaService
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(cfg *config.Config) *QuotaHandler {
+	return &QuotaHandler{
+		quotaService: services.NewQuotaService(&cfg.Quota),
+	}
+}
+
+// GetUserStorage handles getting a user's storage usage and quota
+func (h *QuotaHandler) GetUserStorage(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	usage, err := h.quotaService.GetUsage(userID)
+	if err != nil {
+		if err == services.ErrUserNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"storage": usage})
+}
+
+// SetUserQuota handles giving a user their own storage quota
+func (h *QuotaHandler) SetUserQuota(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	var req services.QuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	usage, err := h.quotaService.SetUserQuota(userID, req)
+	if err != nil {
+		switch err {
+		case services.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrInvalidQuota:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"storage": usage})
+}
+
+// ListUniversityQuotas handles listing the quotas a university overrides
+func (h *QuotaHandler) ListUniversityQuotas(c *gin.Context) {
+	universityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid university id"})
+		return
+	}
+
+	quotas, err := h.quotaService.ListUniversityQuotas(universityID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quotas": quotas})
+}
+
+// SetUniversityQuota handles overriding the quota of a role at a university
+func (h *QuotaHandler) SetUniversityQuota(c *gin.Context) {
+	universityID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid university id"})
+		return
+	}
+
+	var req services.QuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quota, err := h.quotaService.SetUniversityQuota(universityID, models.UserRole(c.Param("role")), req)
+	if err != nil {
+		switch err {
+		case services.ErrUniversityNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrInvalidRole, services.ErrInvalidQuota:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"quota": quota})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/config"
 	"github.com/campus-share/backend/internal/services"
 )
 
@@ -15,9 +16,9 @@ type RecommendationHandler struct {
 }
 
 // NewRecommendationHandler creates a new recommendation handler
-func NewRecommendationHandler() *RecommendationHandler {
+func NewRecommendationHandler(cfg *config.Config) *RecommendationHandler {
 	return &RecommendationHandler{
-		recommendationService: services.NewRecommendationService(),
+		recommendationService: services.NewRecommendationService(&cfg.Similar),
 	}
 }
 
@@ -36,8 +37,19 @@ func (h *RecommendationHandler) GetSimilarResources(c *gin.Context) {
 		}
 	}
 
-	resources, err := h.recommendationService.GetSimilarResources(resourceID, limit)
+	var userID *uuid.UUID
+	if uid, exists := c.Get("user_id"); exists {
+		if uidUUID, ok := uid.(uuid.UUID); ok {
+			userID = &uidUUID
+		}
+	}
+
+	resources, err := h.recommendationService.GetSimilarResources(resourceID, userID, limit)
 	if err != nil {
+		if err == services.ErrResourceNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
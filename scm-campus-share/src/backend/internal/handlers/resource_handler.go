@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -27,7 +29,7 @@ func NewResourceHandler(cfg *config.Config) (*ResourceHandler, error) {
 
 	// If S3 is not configured, create a service with nil storage
 	// This will cause errors when trying to upload, but allows server to start
-	resourceService := services.NewResourceService(s3Storage)
+	resourceService := services.NewResourceService(s3Storage, &cfg.Quota, &cfg.Storage)
 
 	return &ResourceHandler{
 		resourceService: resourceService,
@@ -59,6 +61,11 @@ func (h *ResourceHandler) CreateResource(c *gin.Context) {
 	var req services.CreateResourceRequest
 	req.File = *file
 
+	// Files attached besides the main one
+	if form, err := c.MultipartForm(); err == nil {
+		req.Attachments = form.File["attachments"]
+	}
+
 	// Parse other fields from form
 	req.Title = c.PostForm("title")
 	req.Description = c.PostForm("description")
@@ -96,15 +103,26 @@ func (h *ResourceHandler) CreateResource(c *gin.Context) {
 		h.config.Upload.AllowedFileTypes,
 	)
 	if err != nil {
-		if err == services.ErrFileTooLarge || err == services.ErrInvalidFileType {
+		if err == services.ErrFileTooLarge || err == services.ErrInvalidFileType || err == services.ErrTooManyFiles {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
+		if err == services.ErrStorageQuotaExceeded || err == services.ErrFileQuotaExceeded {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"resource": resource})
+	// Warn about copies of the same file that were uploaded before
+	duplicates, err := h.resourceService.FindDuplicates(resource.ContentHash, &userIDUUID, &resource.ID)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		duplicates = []models.Resource{}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"resource": resource, "duplicates": duplicates})
 }
 
 // GetResource handles getting a single resource
@@ -115,7 +133,7 @@ func (h *ResourceHandler) GetResource(c *gin.Context) {
 		return
 	}
 
-	resource, err := h.resourceService.GetResourceByID(resourceID)
+	resource, err := h.resourceService.ViewResource(resourceID, viewerFromContext(c))
 	if err != nil {
 		if err == services.ErrResourceNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -206,24 +224,85 @@ func (h *ResourceHandler) UpdateResource(c *gin.Context) {
 	}
 
 	var req services.UpdateResourceRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if c.ContentType() == "multipart/form-data" {
+		// A multipart request can also replace the file
+		if !bindUpdateForm(c, &req) {
+			return
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	resource, err := h.resourceService.UpdateResource(resourceID, userIDUUID, req)
+	resource, err := h.resourceService.UpdateResource(
+		resourceID,
+		userIDUUID,
+		req,
+		int64(h.config.Upload.MaxFileSizeMB),
+		h.config.Upload.AllowedFileTypes,
+	)
 	if err != nil {
-		if err == services.ErrResourceNotFound || err == services.ErrUnauthorized {
+		switch err {
+		case services.ErrResourceNotFound, services.ErrUnauthorized:
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-			return
+		case services.ErrFileTooLarge, services.ErrInvalidFileType, services.ErrCatalogMismatch,
+			services.ErrCourseNotFound, services.ErrDepartmentNotFound, services.ErrUniversityNotFound:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case services.ErrStorageQuotaExceeded:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"resource": resource})
 }
 
+// bindUpdateForm reads a multipart resource update. It writes an error response
+// and returns false when a field is malformed.
+func bindUpdateForm(c *gin.Context, req *services.UpdateResourceRequest) bool {
+	req.Title = c.PostForm("title")
+	req.Description = c.PostForm("description")
+
+	if resourceType := c.PostForm("type"); resourceType != "" {
+		t := models.ResourceType(resourceType)
+		req.Type = &t
+	}
+
+	if sharingLevel := c.PostForm("sharing_level"); sharingLevel != "" {
+		level := models.SharingLevel(sharingLevel)
+		req.SharingLevel = &level
+	}
+
+	if tags, ok := c.GetPostFormArray("tags"); ok {
+		req.Tags = tags
+	}
+
+	for field, target := range map[string]**uuid.UUID{
+		"university_id": &req.UniversityID,
+		"department_id": &req.DepartmentID,
+		"course_id":     &req.CourseID,
+	} {
+		value := c.PostForm(field)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + field})
+			return false
+		}
+		*target = &id
+	}
+
+	if file, err := c.FormFile("file"); err == nil {
+		req.File = file
+	}
+
+	return true
+}
+
 // DeleteResource handles deleting a resource
 func (h *ResourceHandler) DeleteResource(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -259,20 +338,159 @@ func (h *ResourceHandler) DeleteResource(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "resource deleted successfully"})
 }
 
+// ListTrash handles listing the user's deleted resources that can still be restored
+func (h *ResourceHandler) ListTrash(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	h.listTrash(c, userIDUUID)
+}
+
+// ListUserTrash handles listing any user's deleted resources (admins only)
+func (h *ResourceHandler) ListUserTrash(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	h.listTrash(c, userID)
+}
+
+func (h *ResourceHandler) listTrash(c *gin.Context, userID uuid.UUID) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	resources, total, err := h.resourceService.ListTrash(userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"resources": resources,
+		"total":     total,
+		"page":      page,
+	})
+}
+
+// RestoreResource handles taking a resource out of the trash
+func (h *ResourceHandler) RestoreResource(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isAdmin := role == "admin" || role == "moderator"
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	resource, err := h.resourceService.RestoreResource(resourceID, userIDUUID, isAdmin)
+	if err != nil {
+		switch err {
+		case services.ErrResourceNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrStorageQuotaExceeded, services.ErrFileQuotaExceeded:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"resource": resource})
+}
+
+// PurgeResource handles deleting a resource in the trash for good
+func (h *ResourceHandler) PurgeResource(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isAdmin := role == "admin" || role == "moderator"
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	if err := h.resourceService.PurgeResource(resourceID, userIDUUID, isAdmin); err != nil {
+		if err == services.ErrResourceNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "resource deleted permanently"})
+}
+
 // DownloadResource handles resource download
 func (h *ResourceHandler) DownloadResource(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
 	resourceID, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
 		return
 	}
 
-	url, err := h.resourceService.DownloadResource(resourceID)
+	url, err := h.resourceService.DownloadResource(resourceID, userIDUUID, viewerFromContext(c))
 	if err != nil {
 		if err == services.ErrResourceNotFound {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		if err == services.ErrScanPending {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrFileQuarantined {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -280,3 +498,430 @@ func (h *ResourceHandler) DownloadResource(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"download_url": url})
 }
 
+
+// ListVersions handles listing the versions of a resource
+func (h *ResourceHandler) ListVersions(c *gin.Context) {
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	versions, err := h.resourceService.ListVersions(resourceID, viewerFromContext(c).UserID, isModerator)
+	if err != nil {
+		if err == services.ErrResourceNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"versions": versions})
+}
+
+// UploadVersion handles uploading a new version of a resource
+func (h *ResourceHandler) UploadVersion(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	req := services.UploadVersionRequest{
+		ChangeNote:  c.PostForm("change_note"),
+		MakeCurrent: c.DefaultPostForm("make_current", "true") != "false",
+		File:        *file,
+	}
+
+	version, err := h.resourceService.UploadVersion(
+		resourceID,
+		userIDUUID,
+		req,
+		int64(h.config.Upload.MaxFileSizeMB),
+		h.config.Upload.AllowedFileTypes,
+	)
+	if err != nil {
+		switch err {
+		case services.ErrResourceNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrUnauthorized, services.ErrStorageQuotaExceeded, services.ErrFileQuotaExceeded:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case services.ErrFileTooLarge, services.ErrInvalidFileType:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"version": version})
+}
+
+// SetCurrentVersion handles choosing which version of a resource is current
+func (h *ResourceHandler) SetCurrentVersion(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version number"})
+		return
+	}
+
+	resource, err := h.resourceService.SetCurrentVersion(resourceID, userIDUUID, number)
+	if err != nil {
+		switch err {
+		case services.ErrResourceNotFound, services.ErrVersionNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"resource": resource})
+}
+
+// DownloadVersion handles downloading one version of a resource
+func (h *ResourceHandler) DownloadVersion(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid version number"})
+		return
+	}
+
+	url, err := h.resourceService.DownloadVersion(resourceID, number, userIDUUID, viewerFromContext(c))
+	if err != nil {
+		if err == services.ErrResourceNotFound || err == services.ErrVersionNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrScanPending {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrFileQuarantined {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"download_url": url})
+}
+
+// AddFiles handles attaching more files to a resource
+func (h *ResourceHandler) AddFiles(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required"})
+		return
+	}
+
+	files, err := h.resourceService.AddFiles(
+		resourceID,
+		userIDUUID,
+		form.File["files"],
+		int64(h.config.Upload.MaxFileSizeMB),
+		h.config.Upload.AllowedFileTypes,
+	)
+	if err != nil {
+		switch err {
+		case services.ErrResourceNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrUnauthorized, services.ErrStorageQuotaExceeded, services.ErrFileQuotaExceeded:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case services.ErrFileTooLarge, services.ErrInvalidFileType, services.ErrTooManyFiles:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"files": files})
+}
+
+// RemoveFile handles removing an attached file from a resource
+func (h *ResourceHandler) RemoveFile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file id"})
+		return
+	}
+
+	if err := h.resourceService.RemoveFile(resourceID, fileID, userIDUUID); err != nil {
+		switch err {
+		case services.ErrResourceNotFound, services.ErrFileNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrUnauthorized:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "file removed successfully"})
+}
+
+// DownloadFile handles downloading one attached file of a resource
+func (h *ResourceHandler) DownloadFile(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	fileID, err := uuid.Parse(c.Param("file_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid file id"})
+		return
+	}
+
+	url, err := h.resourceService.DownloadFile(resourceID, fileID, userIDUUID, viewerFromContext(c))
+	if err != nil {
+		if err == services.ErrResourceNotFound || err == services.ErrFileNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrScanPending {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrFileQuarantined {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"download_url": url})
+}
+
+// DownloadBundle handles downloading every file of a resource as a ZIP archive
+func (h *ResourceHandler) DownloadBundle(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	bundle, err := h.resourceService.GetBundle(resourceID, userIDUUID, viewerFromContext(c))
+	if err != nil {
+		if err == services.ErrResourceNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrScanPending {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if err == services.ErrFileQuarantined {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bundle.Name))
+	c.Status(http.StatusOK)
+
+	// The archive is already being sent, so a failure can only cut it short
+	if err := h.resourceService.WriteBundle(bundle, c.Writer); err != nil {
+		fmt.Printf("Warning: failed to send bundle: %v\n", err)
+	}
+}
+
+// FindDuplicates handles looking up resources with the same file before uploading it
+func (h *ResourceHandler) FindDuplicates(c *gin.Context) {
+	contentHash := strings.ToLower(c.Query("sha256"))
+	if len(contentHash) != 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sha256 must be a hex-encoded SHA-256"})
+		return
+	}
+
+	var userID *uuid.UUID
+	if uid, exists := c.Get("user_id"); exists {
+		if uidUUID, ok := uid.(uuid.UUID); ok {
+			userID = &uidUUID
+		}
+	}
+
+	duplicates, err := h.resourceService.FindDuplicates(contentHash, userID, nil)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"duplicates": duplicates})
+}
+
+// ListDuplicateGroups handles listing files uploaded as more than one resource (moderators only)
+func (h *ResourceHandler) ListDuplicateGroups(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	groups, total, err := h.resourceService.ListDuplicateGroups(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"groups": groups,
+		"total":  total,
+		"page":   page,
+	})
+}
+
+// MergeResource handles merging a duplicate resource into another (moderators only)
+func (h *ResourceHandler) MergeResource(c *gin.Context) {
+	resourceID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid resource id"})
+		return
+	}
+
+	var req services.MergeResourceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	resource, err := h.resourceService.MergeResources(resourceID, req.TargetResourceID)
+	if err != nil {
+		switch err {
+		case services.ErrResourceNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrCannotMergeResourceSelf:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"resource": resource})
+}
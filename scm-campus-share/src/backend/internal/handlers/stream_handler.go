@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/config"
+	"github.com/campus-share/backend/internal/realtime"
+	"github.com/campus-share/backend/internal/services"
+)
+
+// StreamHandler serves real-time events over Server-Sent Events
+type StreamHandler struct {
+	hub               *realtime.Hub
+	streamService     *services.StreamService
+	heartbeatInterval time.Duration
+}
+
+// NewStreamHandler creates a new stream handler
+func NewStreamHandler(cfg *config.Config, hub *realtime.Hub) *StreamHandler {
+	heartbeatInterval := cfg.Realtime.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = 25 * time.Second
+	}
+
+	return &StreamHandler{
+		hub:               hub,
+		streamService:     services.NewStreamService(),
+		heartbeatInterval: heartbeatInterval,
+	}
+}
+
+// Stream handles an event stream for the topics listed in the topics query parameter
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	userIDUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+
+	role, _ := c.Get("user_role")
+	isModerator := role == "admin" || role == "moderator"
+
+	var requested []string
+	if topics := c.Query("topics"); topics != "" {
+		requested = strings.Split(topics, ",")
+	}
+
+	topics, err := h.streamService.ResolveTopics(userIDUUID, isModerator, requested)
+	if err != nil {
+		switch err {
+		case services.ErrTopicNotFound, services.ErrResourceNotFound:
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case services.ErrInvalidStreamTopic, services.ErrTooManyStreamTopics, services.ErrNoStreamTopics:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	// EventSource sends Last-Event-ID when it reconnects; the query parameter
+	// lets clients resume after a page reload
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	var since uint64
+	if lastEventID != "" {
+		if id, err := strconv.ParseUint(lastEventID, 10, 64); err == nil {
+			since = id
+		}
+	}
+
+	sub, replay, complete := h.hub.Subscribe(topics, since)
+	defer sub.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds())
+
+	// Tell the client it missed events and should refetch what it is showing
+	if !complete {
+		fmt.Fprint(w, "event: resync\ndata: {}\n\n")
+	}
+	for _, event := range replay {
+		if err := writeEvent(w, event); err != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and resumes
+				return
+			}
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		w.Flush()
+	}
+}
+
+// writeEvent writes an event in text/event-stream format
+func writeEvent(w io.Writer, event realtime.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/services"
+)
+
+// TrendingHandler handles trending content HTTP requests
+type TrendingHandler struct {
+	engagementService *services.EngagementService
+}
+
+// NewTrendingHandler creates a new trending handler
+func NewTrendingHandler() *TrendingHandler {
+	return &TrendingHandler{
+		engagementService: services.NewEngagementService(),
+	}
+}
+
+// GetTrending handles getting trending resources and topics
+func (h *TrendingHandler) GetTrending(c *gin.Context) {
+	var req services.TrendingRequest
+
+	if universityID := c.Query("university_id"); universityID != "" {
+		id, err := uuid.Parse(universityID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid university id"})
+			return
+		}
+		req.UniversityID = &id
+	}
+	if departmentID := c.Query("department_id"); departmentID != "" {
+		id, err := uuid.Parse(departmentID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid department id"})
+			return
+		}
+		req.DepartmentID = &id
+	}
+	if courseID := c.Query("course_id"); courseID != "" {
+		id, err := uuid.Parse(courseID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid course id"})
+			return
+		}
+		req.CourseID = &id
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = l
+		}
+	}
+
+	var userID *uuid.UUID
+	if uid, exists := c.Get("user_id"); exists {
+		if uidUUID, ok := uid.(uuid.UUID); ok {
+			userID = &uidUUID
+		}
+	}
+
+	contentType := c.DefaultQuery("type", "all")
+	if contentType != "all" && contentType != "resources" && contentType != "topics" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be resources, topics or all"})
+		return
+	}
+
+	response := gin.H{}
+
+	if contentType != "topics" {
+		resources, err := h.engagementService.GetTrendingResources(req, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response["resources"] = resources
+	}
+
+	if contentType != "resources" {
+		topics, err := h.engagementService.GetTrendingTopics(req)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response["topics"] = topics
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/services"
+)
+
+// viewerFromContext describes who made a request, for counting views and downloads
+func viewerFromContext(c *gin.Context) services.Viewer {
+	viewer := services.Viewer{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Prefetch:  isPrefetch(c),
+		Source:    models.EngagementSource(c.Query("source")),
+	}
+
+	if uid, exists := c.Get("user_id"); exists {
+		if uidUUID, ok := uid.(uuid.UUID); ok {
+			viewer.UserID = &uidUUID
+		}
+	}
+
+	return viewer
+}
+
+// isPrefetch reports whether a request was made ahead of the user opening the page.
+// Browsers mark prefetches with a Purpose header; clients prefetching from a list
+// should send the same header or prefetch=1.
+func isPrefetch(c *gin.Context) bool {
+	if c.Query("prefetch") == "1" || c.Query("prefetch") == "true" {
+		return true
+	}
+
+	for _, header := range []string{"Sec-Purpose", "Purpose", "X-Purpose", "X-Moz"} {
+		value := strings.ToLower(c.GetHeader(header))
+		if strings.Contains(value, "prefetch") || strings.Contains(value, "preview") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,32 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var (
+	htmlTemplates = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/*.html"))
+	textTemplates = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/*.txt"))
+)
+
+// Render renders the plain-text and HTML versions of a template, for example
+// "digest" renders templates/digest.txt and templates/digest.html
+func Render(name string, data interface{}) (text string, html string, err error) {
+	var textBuf, htmlBuf bytes.Buffer
+
+	if err := textTemplates.ExecuteTemplate(&textBuf, name+".txt", data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s text template: %w", name, err)
+	}
+	if err := htmlTemplates.ExecuteTemplate(&htmlBuf, name+".html", data); err != nil {
+		return "", "", fmt.Errorf("failed to render %s html template: %w", name, err)
+	}
+
+	return textBuf.String(), htmlBuf.String(), nil
+}
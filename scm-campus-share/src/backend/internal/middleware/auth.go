@@ -1,16 +1,20 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/campus-share/backend/internal/config"
+	"github.com/campus-share/backend/internal/services"
 	"github.com/campus-share/backend/pkg/jwt"
 )
 
-// AuthMiddleware validates JWT tokens
+// AuthMiddleware validates JWT tokens and rejects suspended accounts
 func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	banService := services.NewBanService()
+
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -35,6 +39,27 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
+		// Tokens issued before a ban stay valid, so check the account on every request.
+		// Expired suspensions are lifted here.
+		if err := banService.CheckUserBan(claims.UserID); err != nil {
+			var banErr *services.BannedError
+			switch {
+			case errors.As(err, &banErr):
+				c.JSON(http.StatusForbidden, gin.H{
+					"error":        banErr.Error(),
+					"ban_reason":   banErr.Reason,
+					"banned_until": banErr.Until,
+					"can_appeal":   banErr.CanAppeal,
+				})
+			case errors.Is(err, services.ErrUserNotFound):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			}
+			c.Abort()
+			return
+		}
+
 		// Store claims in context
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
@@ -44,8 +69,27 @@ func AuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 	}
 }
 
-// OptionalAuthMiddleware validates JWT tokens if present, but doesn't require them
+// StreamAuthMiddleware authenticates event streams. Browsers' EventSource cannot
+// set headers, so the token may also be passed in the access_token query parameter.
+func StreamAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	auth := AuthMiddleware(cfg)
+
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			if token := c.Query("access_token"); token != "" {
+				c.Request.Header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		auth(c)
+	}
+}
+
+// OptionalAuthMiddleware validates JWT tokens if present, but doesn't require them.
+// Suspended users are treated as anonymous.
 func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+	banService := services.NewBanService()
+
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -61,7 +105,7 @@ func OptionalAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 
 		token := parts[1]
 		claims, err := jwt.ValidateToken(token, cfg.JWT.Secret)
-		if err == nil {
+		if err == nil && banService.CheckUserBan(claims.UserID) == nil {
 			c.Set("user_id", claims.UserID)
 			c.Set("user_email", claims.Email)
 			c.Set("user_role", claims.Role)
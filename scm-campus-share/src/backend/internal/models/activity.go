@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ActivityType represents the kind of action shown in the activity feed
+type ActivityType string
+
+const (
+	ActivityTypeResourceUploaded ActivityType = "resource_uploaded"
+	ActivityTypeTopicCreated     ActivityType = "topic_created"
+	ActivityTypeAnswerAccepted   ActivityType = "answer_accepted"
+	ActivityTypeResourceRated    ActivityType = "resource_rated"
+	ActivityTypeUserFollowed     ActivityType = "user_followed"
+)
+
+// ActivityTypes lists every activity type
+var ActivityTypes = []ActivityType{
+	ActivityTypeResourceUploaded,
+	ActivityTypeTopicCreated,
+	ActivityTypeAnswerAccepted,
+	ActivityTypeResourceRated,
+	ActivityTypeUserFollowed,
+}
+
+// Activity records something a user did, for the activity feeds of their
+// followers and of users watching the course it happened in
+type Activity struct {
+	ID      uuid.UUID    `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ActorID uuid.UUID    `gorm:"type:uuid;not null;index:idx_activities_actor_created,priority:1" json:"actor_id"`
+	Actor   User         `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	Type    ActivityType `gorm:"type:varchar(30);not null" json:"type"`
+
+	// Polymorphic reference to the object acted on
+	ObjectType string    `gorm:"type:varchar(30);not null;index:idx_activities_object,priority:1" json:"object_type"` // "resource", "topic", "reply", "rating", "user"
+	ObjectID   uuid.UUID `gorm:"type:uuid;not null;index:idx_activities_object,priority:2" json:"object_id"`
+
+	// Course the activity belongs to, if any, so course watchers see it
+	CourseID *uuid.UUID `gorm:"type:uuid;index:idx_activities_course_created,priority:1" json:"course_id,omitempty"`
+
+	CreatedAt time.Time `gorm:"index:idx_activities_actor_created,priority:2;index:idx_activities_course_created,priority:2" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (a *Activity) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (Activity) TableName() string {
+	return "activities"
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PlatformMetrics are the platform activity figures for one day. Activity is
+// attributed to the university of the user who performed it.
+type PlatformMetrics struct {
+	Signups   int64 `gorm:"not null;default:0" json:"signups"`
+	Uploads   int64 `gorm:"not null;default:0" json:"uploads"`
+	Views     int64 `gorm:"not null;default:0" json:"views"`
+	Downloads int64 `gorm:"not null;default:0" json:"downloads"`
+
+	// Users active on the day, the 7 days and the 30 days up to and including it
+	DailyActiveUsers   int64 `gorm:"not null;default:0" json:"daily_active_users"`
+	WeeklyActiveUsers  int64 `gorm:"not null;default:0" json:"weekly_active_users"`
+	MonthlyActiveUsers int64 `gorm:"not null;default:0" json:"monthly_active_users"`
+
+	ReportsOpened   int64 `gorm:"not null;default:0" json:"reports_opened"`
+	ReportsResolved int64 `gorm:"not null;default:0" json:"reports_resolved"`
+	// Average time from report to decision for reports resolved on the day
+	AvgResolutionHours float64 `gorm:"not null;default:0" json:"avg_resolution_hours"`
+}
+
+// DailyMetric is the nightly rollup of platform activity for one day, either
+// for one university or, with a nil UniversityID, for the whole platform
+type DailyMetric struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Date         time.Time `gorm:"type:date;not null;uniqueIndex:idx_daily_metrics_date_university,priority:1" json:"date"`
+	UniversityID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_daily_metrics_date_university,priority:2" json:"university_id"`
+
+	PlatformMetrics `gorm:"embedded"`
+
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (dm *DailyMetric) BeforeCreate(tx *gorm.DB) error {
+	if dm.ID == uuid.Nil {
+		dm.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (DailyMetric) TableName() string {
+	return "daily_metrics"
+}
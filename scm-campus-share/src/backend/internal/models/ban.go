@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserBan represents a suspension of a user account.
+// A nil ExpiresAt means the ban is permanent until lifted by a moderator.
+type UserBan struct {
+	ID       uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID   uuid.UUID  `gorm:"type:uuid;not null;index" json:"user_id"`
+	User     User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	BannedBy *uuid.UUID `gorm:"type:uuid" json:"banned_by,omitempty"`
+
+	Reason    string     `gorm:"type:text;not null" json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+
+	// Set when the ban ends early (unban, approved appeal) or expires
+	LiftedAt *time.Time `json:"lifted_at,omitempty"`
+	LiftedBy *uuid.UUID `gorm:"type:uuid" json:"lifted_by,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Relationships
+	Appeal *BanAppeal `gorm:"foreignKey:BanID" json:"appeal,omitempty"`
+}
+
+// BeforeCreate hook to generate UUID
+func (b *UserBan) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (UserBan) TableName() string {
+	return "user_bans"
+}
+
+// AppealStatus represents the status of a ban appeal
+type AppealStatus string
+
+const (
+	AppealStatusPending  AppealStatus = "pending"
+	AppealStatusApproved AppealStatus = "approved"
+	AppealStatusRejected AppealStatus = "rejected"
+)
+
+// BanAppeal represents a banned user's request to have a ban lifted.
+// Only one appeal may be filed per ban.
+type BanAppeal struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	BanID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"ban_id"`
+	Ban    *UserBan  `gorm:"foreignKey:BanID" json:"ban,omitempty"`
+	UserID uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+
+	Message string       `gorm:"type:text;not null" json:"message"`
+	Status  AppealStatus `gorm:"type:varchar(20);default:'pending'" json:"status"`
+
+	// Admin who handled the appeal
+	ReviewedBy *uuid.UUID `gorm:"type:uuid" json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	AdminNotes string     `gorm:"type:text" json:"admin_notes,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID
+func (a *BanAppeal) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (BanAppeal) TableName() string {
+	return "ban_appeals"
+}
@@ -14,6 +14,13 @@ type Bookmark struct {
 	Resource   Resource  `gorm:"foreignKey:ResourceID" json:"resource,omitempty"`
 	UserID     uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
 	User       User      `gorm:"foreignKey:UserID" json:"-"`
+
+	// Folder the bookmark is filed in, if any
+	FolderID *uuid.UUID      `gorm:"type:uuid;index" json:"folder_id"`
+	Folder   *BookmarkFolder `gorm:"foreignKey:FolderID" json:"folder,omitempty"`
+
+	// Private note, only shown to the user who bookmarked
+	Note string `gorm:"type:text" json:"note"`
 	
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -34,3 +41,30 @@ func (Bookmark) TableName() string {
 }
 
 
+
+// BookmarkFolder is a folder a user files their bookmarks in
+type BookmarkFolder struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index" json:"user_id"`
+	User   User      `gorm:"foreignKey:UserID" json:"-"`
+	Name   string    `gorm:"not null" json:"name"`
+
+	BookmarkCount int64 `gorm:"-" json:"bookmark_count"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID
+func (f *BookmarkFolder) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (BookmarkFolder) TableName() string {
+	return "bookmark_folders"
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CollectionVisibility represents who can see a collection
+type CollectionVisibility string
+
+const (
+	CollectionVisibilityPrivate CollectionVisibility = "private" // Only the owner
+	CollectionVisibilityLink    CollectionVisibility = "link"    // Anyone with the link, but not listed
+	CollectionVisibilityPublic  CollectionVisibility = "public"  // Listed in search and on the owner's profile
+)
+
+// Collection is a user-created, ordered list of resources such as a study
+// playlist
+type Collection struct {
+	ID          uuid.UUID            `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID      uuid.UUID            `gorm:"type:uuid;not null;index" json:"user_id"`
+	User        User                 `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Title       string               `gorm:"not null" json:"title"`
+	Description string               `gorm:"type:text" json:"description"`
+	Visibility  CollectionVisibility `gorm:"type:varchar(20);default:'private';index" json:"visibility"`
+
+	// Collection this one was copied from, if any
+	CopiedFromID *uuid.UUID `gorm:"type:uuid" json:"copied_from_id,omitempty"`
+
+	// Statistics
+	ItemCount     int `gorm:"default:0" json:"item_count"`
+	FollowerCount int `gorm:"default:0" json:"follower_count"`
+
+	Items []CollectionItem `gorm:"foreignKey:CollectionID" json:"items,omitempty"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID
+func (c *Collection) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionItem is a resource in a collection. Items are ordered by position.
+type CollectionItem struct {
+	ID           uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CollectionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_collection_items_collection_resource,priority:1" json:"collection_id"`
+	ResourceID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_collection_items_collection_resource,priority:2;index" json:"resource_id"`
+	Resource     Resource  `gorm:"foreignKey:ResourceID" json:"resource,omitempty"`
+	Position     int       `gorm:"not null" json:"position"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (i *CollectionItem) BeforeCreate(tx *gorm.DB) error {
+	if i.ID == uuid.Nil {
+		i.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (CollectionItem) TableName() string {
+	return "collection_items"
+}
+
+// CollectionFollow represents a user following someone else's collection
+type CollectionFollow struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CollectionID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_collection_follows_collection_user,priority:1" json:"collection_id"`
+	Collection   Collection `gorm:"foreignKey:CollectionID" json:"collection,omitempty"`
+	UserID       uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_collection_follows_collection_user,priority:2;index" json:"user_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (f *CollectionFollow) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (CollectionFollow) TableName() string {
+	return "collection_follows"
+}
@@ -40,4 +40,50 @@ func (Course) TableName() string {
 	return "courses"
 }
 
+// CourseTA marks a user as a teaching assistant for a course
+type CourseTA struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CourseID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_course_tas_course_user" json:"course_id"`
+	Course   Course    `gorm:"foreignKey:CourseID" json:"-"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_course_tas_course_user" json:"user_id"`
+	User     User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
 
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (ct *CourseTA) BeforeCreate(tx *gorm.DB) error {
+	if ct.ID == uuid.Nil {
+		ct.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (CourseTA) TableName() string {
+	return "course_tas"
+}
+
+// CourseWatch subscribes a user to new resources in a course
+type CourseWatch struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_course_watches_user_course" json:"user_id"`
+	User     User      `gorm:"foreignKey:UserID" json:"-"`
+	CourseID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_course_watches_user_course;index" json:"course_id"`
+	Course   Course    `gorm:"foreignKey:CourseID" json:"course,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (cw *CourseWatch) BeforeCreate(tx *gorm.DB) error {
+	if cw.ID == uuid.Nil {
+		cw.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (CourseWatch) TableName() string {
+	return "course_watches"
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// DigestFrequency represents how often a user receives the email digest
+type DigestFrequency string
+
+const (
+	DigestFrequencyNone   DigestFrequency = "none"
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+// DigestPreference stores a user's email digest subscription.
+// Users without a row do not receive digests.
+type DigestPreference struct {
+	ID        uuid.UUID       `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID    uuid.UUID       `gorm:"type:uuid;not null;uniqueIndex" json:"user_id"`
+	User      User            `gorm:"foreignKey:UserID" json:"-"`
+	Frequency DigestFrequency `gorm:"type:varchar(20);not null;default:'none';index" json:"frequency"`
+
+	// Secret used in unsubscribe links so users can opt out without logging in
+	UnsubscribeToken string `gorm:"type:varchar(64);not null;uniqueIndex" json:"-"`
+
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (dp *DigestPreference) BeforeCreate(tx *gorm.DB) error {
+	if dp.ID == uuid.Nil {
+		dp.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (DigestPreference) TableName() string {
+	return "digest_preferences"
+}
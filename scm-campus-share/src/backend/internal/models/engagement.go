@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// EngagementType represents a kind of interaction with a resource or topic
+type EngagementType string
+
+const (
+	EngagementTypeView     EngagementType = "view"
+	EngagementTypeDownload EngagementType = "download"
+	EngagementTypeBookmark EngagementType = "bookmark"
+	EngagementTypeVote     EngagementType = "vote"
+	EngagementTypeReply    EngagementType = "reply"
+)
+
+// EngagementSource is where a viewer came from when they viewed or downloaded something
+type EngagementSource string
+
+const (
+	EngagementSourceSearch         EngagementSource = "search"
+	EngagementSourceFeed           EngagementSource = "feed"
+	EngagementSourceRecommendation EngagementSource = "recommendation"
+	EngagementSourceDirect         EngagementSource = "direct"
+)
+
+// EngagementSources lists all engagement sources
+var EngagementSources = []EngagementSource{
+	EngagementSourceSearch,
+	EngagementSourceFeed,
+	EngagementSourceRecommendation,
+	EngagementSourceDirect,
+}
+
+// EngagementEvent records a single timestamped interaction with a resource or
+// topic. Trending scores are computed from recent events, and the view and
+// download counters of resources and topics are aggregates of these events.
+type EngagementEvent struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	EntityType string         `gorm:"type:varchar(20);not null;index:idx_engagement_events_entity,priority:1" json:"entity_type"` // "resource" or "topic"
+	EntityID   uuid.UUID      `gorm:"type:uuid;not null;index:idx_engagement_events_entity,priority:2" json:"entity_id"`
+	UserID     *uuid.UUID     `gorm:"type:uuid;index" json:"user_id,omitempty"`
+	Type       EngagementType `gorm:"type:varchar(20);not null" json:"type"`
+
+	// Votes record the net change of the vote (-2 to 2); other events are 1
+	Value int `gorm:"not null;default:1" json:"value"`
+
+	// Where the viewer came from; set for views and downloads only
+	Source EngagementSource `gorm:"type:varchar(20)" json:"source,omitempty"`
+
+	// Views and downloads count once per viewer and time window; the key
+	// identifies that window. Other events have no key.
+	DedupKey *string `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+
+	CreatedAt time.Time `gorm:"index:idx_engagement_events_entity,priority:3;index" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (ee *EngagementEvent) BeforeCreate(tx *gorm.DB) error {
+	if ee.ID == uuid.Nil {
+		ee.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (EngagementEvent) TableName() string {
+	return "engagement_events"
+}
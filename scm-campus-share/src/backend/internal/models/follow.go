@@ -10,9 +10,9 @@ import (
 // Follow represents a user following another user
 type Follow struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	FollowerID uuid.UUID `gorm:"type:uuid;not null" json:"follower_id"` // User who is following
+	FollowerID uuid.UUID `gorm:"type:uuid;not null;index" json:"follower_id"` // User who is following
 	Follower    User      `gorm:"foreignKey:FollowerID" json:"follower,omitempty"`
-	FollowingID uuid.UUID `gorm:"type:uuid;not null" json:"following_id"` // User being followed
+	FollowingID uuid.UUID `gorm:"type:uuid;not null;index" json:"following_id"` // User being followed
 	Following   User      `gorm:"foreignKey:FollowingID" json:"following,omitempty"`
 	
 	CreatedAt time.Time `json:"created_at"`
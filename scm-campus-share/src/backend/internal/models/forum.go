@@ -7,11 +7,24 @@ import (
 	"gorm.io/gorm"
 )
 
+// TopicType represents the kind of forum topic
+type TopicType string
+
+const (
+	TopicTypeDiscussion TopicType = "discussion"
+	TopicTypeQuestion   TopicType = "question" // Q&A mode: one reply can be accepted as the answer
+)
+
 // ForumTopic represents a discussion topic/thread
 type ForumTopic struct {
 	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
 	Title       string    `gorm:"not null" json:"title"`
 	Content     string    `gorm:"type:text;not null" json:"content"`
+	Type        TopicType `gorm:"type:varchar(20);default:'discussion'" json:"type"`
+
+	// Q&A: the reply accepted as the answer, if any
+	AcceptedReplyID *uuid.UUID `gorm:"type:uuid" json:"accepted_reply_id,omitempty"`
+	SolvedAt        *time.Time `json:"solved_at,omitempty"`
 	
 	// Topic categorization
 	CourseID     *uuid.UUID `gorm:"type:uuid" json:"course_id,omitempty"`
@@ -35,6 +48,11 @@ type ForumTopic struct {
 	IsPinned   bool `gorm:"default:false" json:"is_pinned"`
 	IsLocked   bool `gorm:"default:false" json:"is_locked"`
 	IsApproved bool `gorm:"default:true" json:"is_approved"`
+
+	// Editing and deletion. Deleted topics stay as tombstones so their threads remain readable.
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	IsDeleted bool       `gorm:"default:false" json:"is_deleted"`
+	DeletedBy *uuid.UUID `gorm:"type:uuid" json:"-"`
 	
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -78,8 +96,16 @@ type ForumReply struct {
 	UpvoteCount   int `gorm:"default:0" json:"upvote_count"`
 	DownvoteCount int `gorm:"default:0" json:"downvote_count"`
 	
+	// Q&A: set when this reply is the accepted answer to a question topic
+	IsAccepted bool `gorm:"default:false" json:"is_accepted"`
+	
 	// Moderation
 	IsApproved bool `gorm:"default:true" json:"is_approved"`
+
+	// Editing and deletion. Deleted replies stay as tombstones so nested replies keep their place.
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	IsDeleted bool       `gorm:"default:false" json:"is_deleted"`
+	DeletedBy *uuid.UUID `gorm:"type:uuid" json:"-"`
 	
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
@@ -102,18 +128,19 @@ func (ForumReply) TableName() string {
 	return "forum_replies"
 }
 
-// ForumVote represents a vote (upvote/downvote) on a topic or reply
+// ForumVote represents a vote (upvote/downvote) on a topic or reply.
+// A user has at most one vote per topic or reply.
 type ForumVote struct {
 	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
-	UserID uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_forum_votes_user_votable" json:"user_id"`
 	User   User      `gorm:"foreignKey:UserID" json:"-"`
 	
 	// Vote type: true = upvote, false = downvote
 	IsUpvote bool `gorm:"not null" json:"is_upvote"`
 	
 	// Polymorphic association - can vote on topic or reply
-	VotableType string    `gorm:"type:varchar(20);not null" json:"votable_type"` // "topic" or "reply"
-	VotableID   uuid.UUID `gorm:"type:uuid;not null" json:"votable_id"`
+	VotableType string    `gorm:"type:varchar(20);not null;uniqueIndex:idx_forum_votes_user_votable" json:"votable_type"` // "topic" or "reply"
+	VotableID   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_forum_votes_user_votable" json:"votable_id"`
 	
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -131,3 +158,37 @@ func (ForumVote) TableName() string {
 	return "forum_votes"
 }
 
+// ForumRevision stores the previous version of a topic or reply each time it is edited or deleted
+type ForumRevision struct {
+	ID uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+
+	// Polymorphic association - revision of a topic or reply
+	RevisableType string    `gorm:"type:varchar(20);not null;index:idx_forum_revisions_revisable" json:"revisable_type"` // "topic" or "reply"
+	RevisableID   uuid.UUID `gorm:"type:uuid;not null;index:idx_forum_revisions_revisable" json:"revisable_id"`
+
+	// User who made the change (author or moderator)
+	EditorID uuid.UUID `gorm:"type:uuid;not null" json:"editor_id"`
+	Editor   User      `gorm:"foreignKey:EditorID" json:"editor,omitempty"`
+
+	// Action is "edit" or "delete"
+	Action string `gorm:"type:varchar(20);not null" json:"action"`
+
+	// Content before the change; Title is only set for topics
+	Title   string `json:"title,omitempty"`
+	Content string `gorm:"type:text;not null" json:"content"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (fr *ForumRevision) BeforeCreate(tx *gorm.DB) error {
+	if fr.ID == uuid.Nil {
+		fr.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (ForumRevision) TableName() string {
+	return "forum_revisions"
+}
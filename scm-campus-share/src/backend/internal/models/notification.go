@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationType represents the kind of event a notification is about
+type NotificationType string
+
+const (
+	NotificationTypeComment           NotificationType = "comment"            // Someone commented on your upload
+	NotificationTypeCommentReply      NotificationType = "comment_reply"      // Someone replied to your comment
+	NotificationTypeForumReply        NotificationType = "forum_reply"        // Someone replied to your topic or reply
+	NotificationTypeFollow            NotificationType = "follow"             // Someone followed you
+	NotificationTypeReportResolved    NotificationType = "report_resolved"    // A report you filed was approved or rejected
+	NotificationTypeResourceUpdated   NotificationType = "resource_updated"   // A resource you bookmarked has a new version
+	NotificationTypeUploadQuarantined NotificationType = "upload_quarantined" // Malware was found in your upload
+	NotificationTypeCollectionFollow  NotificationType = "collection_follow"  // Someone followed your collection
+)
+
+// NotificationTypes lists every notification type a user can configure
+var NotificationTypes = []NotificationType{
+	NotificationTypeComment,
+	NotificationTypeCommentReply,
+	NotificationTypeForumReply,
+	NotificationTypeFollow,
+	NotificationTypeReportResolved,
+	NotificationTypeResourceUpdated,
+	NotificationTypeUploadQuarantined,
+	NotificationTypeCollectionFollow,
+}
+
+// Notification represents an in-app notification for a user
+type Notification struct {
+	ID     uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID uuid.UUID `gorm:"type:uuid;not null;index:idx_notifications_user_read" json:"user_id"` // Recipient
+	User   User      `gorm:"foreignKey:UserID" json:"-"`
+
+	// User whose action triggered the notification, if any
+	ActorID *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
+	Actor   *User      `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+
+	Type    NotificationType `gorm:"type:varchar(30);not null" json:"type"`
+	Message string           `gorm:"type:text;not null" json:"message"`
+
+	// Polymorphic link to the object the notification is about
+	EntityType string     `gorm:"type:varchar(30)" json:"entity_type,omitempty"` // "resource", "comment", "topic", "reply", "user", "report"
+	EntityID   *uuid.UUID `gorm:"type:uuid" json:"entity_id,omitempty"`
+
+	ReadAt *time.Time `gorm:"index:idx_notifications_user_read" json:"read_at,omitempty"`
+
+	CreatedAt time.Time      `gorm:"index" json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPreference stores whether a user wants notifications of a given type.
+// Types without a row are enabled.
+type NotificationPreference struct {
+	ID      uuid.UUID        `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID  uuid.UUID        `gorm:"type:uuid;not null;uniqueIndex:idx_notification_prefs_user_type" json:"user_id"`
+	User    User             `gorm:"foreignKey:UserID" json:"-"`
+	Type    NotificationType `gorm:"type:varchar(30);not null;uniqueIndex:idx_notification_prefs_user_type" json:"type"`
+	Enabled bool             `gorm:"not null" json:"enabled"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (np *NotificationPreference) BeforeCreate(tx *gorm.DB) error {
+	if np.ID == uuid.Nil {
+		np.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UniversityQuota overrides the default storage quota of a role for the members
+// of a university. A nil limit keeps the role's default; 0 means unlimited.
+type UniversityQuota struct {
+	ID           uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UniversityID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_university_quotas_university_role,priority:1" json:"university_id"`
+	University   University `gorm:"foreignKey:UniversityID" json:"-"`
+	Role         UserRole   `gorm:"type:varchar(20);not null;uniqueIndex:idx_university_quotas_university_role,priority:2" json:"role"`
+
+	MaxMB    *int `json:"max_mb"`
+	MaxFiles *int `json:"max_files"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (q *UniversityQuota) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (UniversityQuota) TableName() string {
+	return "university_quotas"
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ResourceDownload records that a user has downloaded a resource.
+// There is one row per user and resource, however often it was downloaded.
+type ResourceDownload struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_resource_downloads_user_resource" json:"user_id"`
+	ResourceID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_resource_downloads_user_resource;index" json:"resource_id"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (rd *ResourceDownload) BeforeCreate(tx *gorm.DB) error {
+	if rd.ID == uuid.Nil {
+		rd.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (ResourceDownload) TableName() string {
+	return "resource_downloads"
+}
+
+// ResourceSimilarity is a precomputed item-to-item score from users who
+// downloaded, bookmarked or rated both resources. Rows are rebuilt as a whole
+// by the recommendation job.
+type ResourceSimilarity struct {
+	ID                uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ResourceID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_resource_similarities_pair,priority:1" json:"resource_id"`
+	SimilarResourceID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_resource_similarities_pair,priority:2" json:"similar_resource_id"`
+
+	Score   float64 `gorm:"not null" json:"score"`   // Cosine similarity of weighted interactions, 0 to 1
+	Support int     `gorm:"not null" json:"support"` // Number of users who interacted with both
+
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (rs *ResourceSimilarity) BeforeCreate(tx *gorm.DB) error {
+	if rs.ID == uuid.Nil {
+		rs.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (ResourceSimilarity) TableName() string {
+	return "resource_similarities"
+}
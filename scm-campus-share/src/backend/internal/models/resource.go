@@ -43,8 +43,20 @@ type Resource struct {
 	FileName    string `gorm:"not null" json:"file_name"`
 	FileSize    int64  `gorm:"not null" json:"file_size"` // in bytes
 	FileType    string `gorm:"not null" json:"file_type"` // MIME type
-	S3Key       string `gorm:"not null" json:"-"`         // S3 object key
+	S3Key       string `gorm:"not null;index" json:"-"`   // S3 object key, shared by identical files
 	S3URL       string `json:"s3_url,omitempty"`          // Pre-signed URL for download
+
+	// SHA-256 of the file, used to spot duplicate uploads
+	ContentHash string `gorm:"type:varchar(64);index" json:"content_hash,omitempty"`
+
+	// Version the file fields above belong to
+	CurrentVersion int `gorm:"not null;default:1" json:"current_version"`
+
+	// Malware scan of the resource's files. Files cannot be downloaded until
+	// every one of them is clean. Resources from before scanning count as clean.
+	ScanStatus    ScanStatus `gorm:"type:varchar(20);not null;default:'clean';index" json:"scan_status"`
+	ScanSignature string     `json:"scan_signature,omitempty"` // Malware found, if infected
+	ScannedAt     *time.Time `json:"scanned_at,omitempty"`
 	
 	// Categorization
 	UniversityID *uuid.UUID `gorm:"type:uuid" json:"university_id,omitempty"`
@@ -61,16 +73,24 @@ type Resource struct {
 	// Statistics
 	DownloadCount int `gorm:"default:0" json:"download_count"`
 	ViewCount     int `gorm:"default:0" json:"view_count"`
+
+	// Whether the user the resource was loaded for bookmarked it
+	IsBookmarked bool `gorm:"-" json:"is_bookmarked"`
 	
 	// Timestamps
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
+	// When the storage garbage collector deleted the files of the resource,
+	// which deleted resources keep for a retention period
+	FilesPurgedAt *time.Time `json:"-"`
+
 	// Relationships
 	Comments []Comment `gorm:"foreignKey:ResourceID" json:"comments,omitempty"`
 	Ratings  []Rating  `gorm:"foreignKey:ResourceID" json:"ratings,omitempty"`
 	Tags     []ResourceTag `gorm:"foreignKey:ResourceID" json:"tags,omitempty"`
+	Files    []ResourceFile `gorm:"foreignKey:ResourceID" json:"files,omitempty"` // Attached besides the main file
 }
 
 // BeforeCreate hook to generate UUID
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ResourceFile is a file attached to a resource in addition to its main file,
+// such as the data files that go with a lab assignment
+type ResourceFile struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ResourceID uuid.UUID `gorm:"type:uuid;not null;index" json:"resource_id"`
+
+	// File information
+	FileName string `gorm:"not null" json:"file_name"`
+	FileSize int64  `gorm:"not null" json:"file_size"` // in bytes
+	FileType string `gorm:"not null" json:"file_type"` // MIME type
+	S3Key    string `gorm:"not null;index" json:"-"`   // S3 object key, shared by identical files
+
+	// SHA-256 of the file
+	ContentHash string `gorm:"type:varchar(64);index" json:"content_hash,omitempty"`
+
+	// Order in which the files are listed
+	Position int `gorm:"not null;default:0" json:"position"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (f *ResourceFile) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (ResourceFile) TableName() string {
+	return "resource_files"
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ResourceVersion is one revision of a resource's file. The resource's own file
+// fields mirror the version it currently points to.
+type ResourceVersion struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ResourceID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_resource_versions_resource_number,priority:1" json:"resource_id"`
+	Number     int       `gorm:"not null;uniqueIndex:idx_resource_versions_resource_number,priority:2" json:"number"` // 1 for the original upload
+	UserID     uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`                                                   // Uploader
+	User       User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+
+	// What changed since the previous version
+	ChangeNote string `gorm:"type:text" json:"change_note,omitempty"`
+
+	// File information
+	FileName string `gorm:"not null" json:"file_name"`
+	FileSize int64  `gorm:"not null" json:"file_size"` // in bytes
+	FileType string `gorm:"not null" json:"file_type"` // MIME type
+	S3Key    string `gorm:"not null;index" json:"-"`   // S3 object key, shared by identical files
+
+	// SHA-256 of the file
+	ContentHash string `gorm:"type:varchar(64);index" json:"content_hash,omitempty"`
+
+	IsCurrent bool `gorm:"-" json:"is_current"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID
+func (v *ResourceVersion) BeforeCreate(tx *gorm.DB) error {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return nil
+}
+
+// TableName specifies the table name
+func (ResourceVersion) TableName() string {
+	return "resource_versions"
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// ScanStatus is where a file is in the malware scan
+type ScanStatus string
+
+const (
+	ScanStatusPending  ScanStatus = "pending"  // Waiting to be scanned
+	ScanStatusClean    ScanStatus = "clean"    // Passed the scan
+	ScanStatusInfected ScanStatus = "infected" // Malware found; the file is quarantined
+	ScanStatusFailed   ScanStatus = "failed"   // Could not be scanned, to be retried
+)
+
+// FileScan is the scan result of a stored file. Identical uploads share one
+// stored file, so each is only scanned once.
+type FileScan struct {
+	S3Key     string     `gorm:"primaryKey" json:"-"`
+	Status    ScanStatus `gorm:"type:varchar(20);not null" json:"status"`
+	Signature string     `json:"signature,omitempty"` // Malware found in the file
+	Error     string     `gorm:"type:text" json:"error,omitempty"`
+
+	// Where an infected file was moved to, out of reach of downloads
+	QuarantineKey string `json:"-"`
+
+	ScannedAt time.Time `gorm:"not null" json:"scanned_at"`
+}
+
+// TableName specifies the table name
+func (FileScan) TableName() string {
+	return "file_scans"
+}
@@ -27,6 +27,9 @@ type User struct {
 	Role         UserRole  `gorm:"type:varchar(20);default:'student'" json:"role"`
 	IsActive     bool      `gorm:"default:true" json:"is_active"`
 	IsBanned     bool      `gorm:"default:false" json:"is_banned"`
+	// Ban details are shown only to the user and admins, through Account
+	BanReason    string     `gorm:"type:text" json:"-"`
+	BannedUntil  *time.Time `json:"-"` // nil with IsBanned means permanent
 	
 	// Profile information
 	UniversityID *uuid.UUID `gorm:"type:uuid" json:"university_id,omitempty"`
@@ -35,6 +38,13 @@ type User struct {
 	Department   *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
 	Year         int         `gorm:"default:1" json:"year,omitempty"`
 	Major        string      `json:"major,omitempty"`
+
+	// Reputation earned from community contributions such as accepted answers
+	Reputation int `gorm:"default:0" json:"reputation"`
+
+	// Storage quota set for this user by an admin; nil uses the university's or role's
+	QuotaMB    *int `json:"-"`
+	QuotaFiles *int `json:"-"`
 	
 	// OAuth
 	GoogleID string `gorm:"uniqueIndex" json:"-"`
@@ -65,3 +75,25 @@ func (User) TableName() string {
 }
 
 
+
+// FullName returns the user's display name
+func (u *User) FullName() string {
+	return u.FirstName + " " + u.LastName
+}
+
+// Account is a user as shown to themselves and to admins, including the
+// details of their ban that are left out wherever else the user appears
+type Account struct {
+	*User
+	BanReason   string     `json:"ban_reason,omitempty"`
+	BannedUntil *time.Time `json:"banned_until,omitempty"`
+}
+
+// Account returns the user with the details only they and admins may see
+func (u *User) Account() Account {
+	return Account{
+		User:        u,
+		BanReason:   u.BanReason,
+		BannedUntil: u.BannedUntil,
+	}
+}
@@ -0,0 +1,86 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBrokerClosed = errors.New("broker is closed")
+)
+
+// Event is a message pushed to clients subscribed to its topic
+type Event struct {
+	// ID is assigned by the hub when the event is delivered and is only
+	// meaningful to the instance that assigned it
+	ID        uint64          `json:"id"`
+	Topic     string          `json:"topic"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// Broker carries published events to every server instance.
+// The in-memory broker only reaches the local process; a broker backed by
+// Redis pub/sub or Postgres LISTEN/NOTIFY can be plugged in to fan out
+// across instances without changing the hub or its callers.
+type Broker interface {
+	// Publish sends an event to all subscribers, including the local one
+	Publish(event Event) error
+	// Subscribe registers a function that receives every published event
+	Subscribe(deliver func(Event)) error
+	// Close stops delivering events
+	Close() error
+}
+
+// MemoryBroker is a Broker that delivers events within the current process
+type MemoryBroker struct {
+	mu          sync.RWMutex
+	subscribers []func(Event)
+	closed      bool
+}
+
+// NewMemoryBroker creates a new in-process broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{}
+}
+
+// Publish delivers an event to every subscriber
+func (b *MemoryBroker) Publish(event Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	for _, deliver := range b.subscribers {
+		deliver(event)
+	}
+	return nil
+}
+
+// Subscribe registers a function that receives every published event
+func (b *MemoryBroker) Subscribe(deliver func(Event)) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	b.subscribers = append(b.subscribers, deliver)
+	return nil
+}
+
+// Close stops delivering events
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	b.subscribers = nil
+	return nil
+}
@@ -0,0 +1,214 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// subscriptionBuffer is how many undelivered events a subscriber may queue.
+// Subscribers that fall further behind are disconnected and must reconnect
+// with their last event ID to catch up from the history.
+const subscriptionBuffer = 64
+
+// Topic names
+const (
+	TopicNotifications = "notifications" // Alias clients use for their own inbox
+)
+
+// UserTopic is the notification inbox of a user
+func UserTopic(userID uuid.UUID) string {
+	return "user:" + userID.String()
+}
+
+// ForumTopic carries new replies in a forum topic
+func ForumTopic(topicID uuid.UUID) string {
+	return "forum_topic:" + topicID.String()
+}
+
+// ResourceTopic carries new comments on a resource
+func ResourceTopic(resourceID uuid.UUID) string {
+	return "resource:" + resourceID.String()
+}
+
+// Hub fans events out from a broker to the streams connected to this instance
+// and keeps a short history so reconnecting clients can resume
+type Hub struct {
+	broker Broker
+
+	mu          sync.Mutex
+	nextID      uint64
+	history     []Event
+	historySize int
+	subscribers map[string]map[*Subscription]struct{}
+}
+
+// Subscription receives events for a set of topics until it is closed
+type Subscription struct {
+	Events <-chan Event
+
+	events chan Event
+	topics []string
+	hub    *Hub
+	closed bool
+}
+
+// NewHub creates a hub that delivers events received from the broker
+func NewHub(broker Broker, historySize int) (*Hub, error) {
+	h := &Hub{
+		broker:      broker,
+		historySize: historySize,
+		subscribers: make(map[string]map[*Subscription]struct{}),
+	}
+
+	if err := broker.Subscribe(h.dispatch); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to broker: %w", err)
+	}
+
+	return h, nil
+}
+
+// Publish sends an event with a JSON-encoded payload to a topic
+func (h *Hub) Publish(topic, eventType string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	return h.broker.Publish(Event{
+		Topic:     topic,
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: time.Now(),
+	})
+}
+
+// Subscribe opens a subscription to the given topics. If lastEventID is set,
+// events after it that are still in the history are returned for replay.
+// complete is false when some events since lastEventID can no longer be
+// replayed and the client should refetch its state.
+func (h *Hub) Subscribe(topics []string, lastEventID uint64) (sub *Subscription, replay []Event, complete bool) {
+	events := make(chan Event, subscriptionBuffer)
+	sub = &Subscription{
+		Events: events,
+		events: events,
+		topics: topics,
+		hub:    h,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, topic := range topics {
+		if h.subscribers[topic] == nil {
+			h.subscribers[topic] = make(map[*Subscription]struct{})
+		}
+		h.subscribers[topic][sub] = struct{}{}
+	}
+
+	complete = true
+	if lastEventID > 0 {
+		// IDs from another instance or before a restart cannot be resumed
+		if lastEventID > h.nextID {
+			complete = false
+		} else if len(h.history) > 0 && h.history[0].ID > lastEventID+1 {
+			complete = false
+		}
+
+		for _, event := range h.history {
+			if event.ID > lastEventID && sub.wants(event.Topic) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	return sub, replay, complete
+}
+
+// Close removes the subscription from the hub and closes its channel
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.remove(s)
+}
+
+func (s *Subscription) wants(topic string) bool {
+	for _, t := range s.topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatch numbers an event, records it and hands it to local subscribers
+func (h *Hub) dispatch(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	event.ID = h.nextID
+
+	if h.historySize > 0 {
+		h.history = append(h.history, event)
+		if len(h.history) > h.historySize {
+			h.history = h.history[len(h.history)-h.historySize:]
+		}
+	}
+
+	for sub := range h.subscribers[event.Topic] {
+		select {
+		case sub.events <- event:
+		default:
+			log.Printf("Warning: dropping slow event stream subscriber")
+			h.remove(sub)
+		}
+	}
+}
+
+// remove unregisters a subscription. The caller must hold h.mu.
+func (h *Hub) remove(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+
+	for _, topic := range sub.topics {
+		delete(h.subscribers[topic], sub)
+		if len(h.subscribers[topic]) == 0 {
+			delete(h.subscribers, topic)
+		}
+	}
+	close(sub.events)
+}
+
+// Close shuts down the broker
+func (h *Hub) Close() error {
+	return h.broker.Close()
+}
+
+// defaultHub is the hub services publish to; it is nil when pushing is disabled,
+// for example in command-line tools
+var defaultHub *Hub
+
+// SetDefault sets the hub used by Publish
+func SetDefault(h *Hub) {
+	defaultHub = h
+}
+
+// Publish sends an event through the default hub if one is configured.
+// Failures are logged so they never block the action that caused the event.
+func Publish(topic, eventType string, data interface{}) {
+	if defaultHub == nil {
+		return
+	}
+
+	if err := defaultHub.Publish(topic, eventType, data); err != nil {
+		log.Printf("Warning: failed to publish %s event: %v", eventType, err)
+	}
+}
@@ -0,0 +1,343 @@
+package services
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var (
+	ErrInvalidCursor       = errors.New("invalid cursor")
+	ErrInvalidActivityType = errors.New("invalid activity type")
+)
+
+// MaxFeedLimit caps how many activities one feed page returns
+const MaxFeedLimit = 100
+
+// ActivityService records user activity and builds activity feeds
+type ActivityService struct{}
+
+// NewActivityService creates a new activity service
+func NewActivityService() *ActivityService {
+	return &ActivityService{}
+}
+
+// Record stores an activity. courseID is set when the activity happened in a course,
+// so that users watching the course see it.
+func (s *ActivityService) Record(actorID uuid.UUID, activityType models.ActivityType, objectType string, objectID uuid.UUID, courseID *uuid.UUID) error {
+	activity := models.Activity{
+		ActorID:    actorID,
+		Type:       activityType,
+		ObjectType: objectType,
+		ObjectID:   objectID,
+		CourseID:   courseID,
+	}
+
+	if err := database.DB.Create(&activity).Error; err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+
+	return nil
+}
+
+// Remove deletes an activity that no longer holds, such as an unfollow or an unaccepted answer
+func (s *ActivityService) Remove(actorID uuid.UUID, activityType models.ActivityType, objectType string, objectID uuid.UUID) error {
+	if err := database.DB.
+		Where("actor_id = ? AND type = ? AND object_type = ? AND object_id = ?", actorID, activityType, objectType, objectID).
+		Delete(&models.Activity{}).Error; err != nil {
+		return fmt.Errorf("failed to remove activity: %w", err)
+	}
+
+	return nil
+}
+
+// FeedRequest represents a request for a page of the activity feed
+type FeedRequest struct {
+	Cursor string
+	Limit  int
+	Types  []models.ActivityType
+}
+
+// FeedItem is an activity together with the object it refers to
+type FeedItem struct {
+	models.Activity
+	Resource *models.Resource   `json:"resource,omitempty"`
+	Topic    *models.ForumTopic `json:"topic,omitempty"`
+	Reply    *models.ForumReply `json:"reply,omitempty"`
+	Rating   *models.Rating     `json:"rating,omitempty"`
+	User     *models.User       `json:"user,omitempty"`
+}
+
+// GetFeed returns activities of the users someone follows and of the courses they watch,
+// newest first. Pages are addressed by an opaque cursor rather than an offset, so they
+// stay stable while new activity arrives and cost the same however deep the client pages.
+// The returned cursor is empty on the last page.
+func (s *ActivityService) GetFeed(userID uuid.UUID, req FeedRequest) ([]FeedItem, string, error) {
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > MaxFeedLimit {
+		req.Limit = MaxFeedLimit
+	}
+
+	for _, activityType := range req.Types {
+		if !isActivityType(activityType) {
+			return nil, "", ErrInvalidActivityType
+		}
+	}
+
+	// Subqueries keep the follow list in the database instead of an IN clause
+	following := database.DB.Model(&models.Follow{}).Select("following_id").Where("follower_id = ?", userID)
+	watching := database.DB.Model(&models.CourseWatch{}).Select("course_id").Where("user_id = ?", userID)
+
+	query := database.DB.Model(&models.Activity{}).
+		Preload("Actor").
+		Where("actor_id IN (?) OR course_id IN (?)", following, watching).
+		Where("actor_id <> ?", userID)
+
+	if len(req.Types) > 0 {
+		query = query.Where("type IN ?", req.Types)
+	}
+
+	if req.Cursor != "" {
+		createdAt, id, err := decodeFeedCursor(req.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(created_at, id) < (?, ?)", createdAt, id)
+	}
+
+	var activities []models.Activity
+	if err := query.Order("created_at DESC, id DESC").Limit(req.Limit + 1).Find(&activities).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to get activity feed: %w", err)
+	}
+
+	nextCursor := ""
+	if len(activities) > req.Limit {
+		activities = activities[:req.Limit]
+		last := activities[len(activities)-1]
+		nextCursor = encodeFeedCursor(last.CreatedAt, last.ID)
+	}
+
+	items, err := s.hydrate(userID, activities)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return items, nextCursor, nil
+}
+
+// hydrate loads the objects of a page of activities in one query per object type.
+// Activities whose object was deleted, hidden or is not visible to the user are dropped.
+func (s *ActivityService) hydrate(userID uuid.UUID, activities []models.Activity) ([]FeedItem, error) {
+	ids := make(map[string][]uuid.UUID)
+	for _, activity := range activities {
+		ids[activity.ObjectType] = append(ids[activity.ObjectType], activity.ObjectID)
+	}
+
+	ratings := make(map[uuid.UUID]*models.Rating)
+	if len(ids["rating"]) > 0 {
+		var found []models.Rating
+		if err := database.DB.Where("id IN ?", ids["rating"]).Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load ratings: %w", err)
+		}
+		for i := range found {
+			ratings[found[i].ID] = &found[i]
+			ids["resource"] = append(ids["resource"], found[i].ResourceID)
+		}
+	}
+
+	resources := make(map[uuid.UUID]*models.Resource)
+	if len(ids["resource"]) > 0 {
+		var found []models.Resource
+		if err := database.DB.
+			Preload("User").
+			Preload("Course").
+			Where("id IN ? AND is_approved = ?", ids["resource"], true).
+			Scopes(visibleResources(&userID)).
+			Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load resources: %w", err)
+		}
+		for i := range found {
+			resources[found[i].ID] = &found[i]
+		}
+	}
+
+	topics := make(map[uuid.UUID]*models.ForumTopic)
+	if len(ids["topic"]) > 0 {
+		var found []models.ForumTopic
+		if err := database.DB.
+			Preload("User").
+			Preload("Course").
+			Where("id IN ? AND is_approved = ? AND is_deleted = ?", ids["topic"], true, false).
+			Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load topics: %w", err)
+		}
+		for i := range found {
+			topics[found[i].ID] = &found[i]
+		}
+	}
+
+	replies := make(map[uuid.UUID]*models.ForumReply)
+	if len(ids["reply"]) > 0 {
+		var found []models.ForumReply
+		if err := database.DB.
+			Preload("User").
+			Preload("Topic").
+			Where("id IN ? AND is_approved = ? AND is_deleted = ?", ids["reply"], true, false).
+			Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load replies: %w", err)
+		}
+		for i := range found {
+			if found[i].Topic.IsApproved && !found[i].Topic.IsDeleted {
+				replies[found[i].ID] = &found[i]
+			}
+		}
+	}
+
+	users := make(map[uuid.UUID]*models.User)
+	if len(ids["user"]) > 0 {
+		var found []models.User
+		if err := database.DB.Where("id IN ?", ids["user"]).Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load users: %w", err)
+		}
+		for i := range found {
+			users[found[i].ID] = &found[i]
+		}
+	}
+
+	items := make([]FeedItem, 0, len(activities))
+	for _, activity := range activities {
+		item := FeedItem{Activity: activity}
+
+		switch activity.ObjectType {
+		case "resource":
+			item.Resource = resources[activity.ObjectID]
+			if item.Resource == nil {
+				continue
+			}
+		case "topic":
+			item.Topic = topics[activity.ObjectID]
+			if item.Topic == nil {
+				continue
+			}
+		case "reply":
+			item.Reply = replies[activity.ObjectID]
+			if item.Reply == nil {
+				continue
+			}
+		case "rating":
+			item.Rating = ratings[activity.ObjectID]
+			if item.Rating == nil || resources[item.Rating.ResourceID] == nil {
+				continue
+			}
+			item.Resource = resources[item.Rating.ResourceID]
+		case "user":
+			item.User = users[activity.ObjectID]
+			if item.User == nil {
+				continue
+			}
+		default:
+			continue
+		}
+
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// Backfill records activities for content created before the activity feed existed.
+// Objects that already have an activity are skipped, so it is safe to run again.
+func (s *ActivityService) Backfill() (int64, error) {
+	statements := []string{
+		`INSERT INTO activities (id, actor_id, type, object_type, object_id, course_id, created_at)
+		SELECT gen_random_uuid(), r.user_id, 'resource_uploaded', 'resource', r.id, r.course_id, r.created_at
+		FROM resources r
+		WHERE r.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM activities a WHERE a.object_type = 'resource' AND a.object_id = r.id AND a.type = 'resource_uploaded')`,
+
+		`INSERT INTO activities (id, actor_id, type, object_type, object_id, course_id, created_at)
+		SELECT gen_random_uuid(), t.user_id, 'topic_created', 'topic', t.id, t.course_id, t.created_at
+		FROM forum_topics t
+		WHERE t.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM activities a WHERE a.object_type = 'topic' AND a.object_id = t.id AND a.type = 'topic_created')`,
+
+		`INSERT INTO activities (id, actor_id, type, object_type, object_id, course_id, created_at)
+		SELECT gen_random_uuid(), r.user_id, 'answer_accepted', 'reply', r.id, t.course_id, COALESCE(t.solved_at, r.created_at)
+		FROM forum_topics t
+		JOIN forum_replies r ON r.id = t.accepted_reply_id
+		WHERE t.deleted_at IS NULL AND r.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM activities a WHERE a.object_type = 'reply' AND a.object_id = r.id AND a.type = 'answer_accepted')`,
+
+		`INSERT INTO activities (id, actor_id, type, object_type, object_id, course_id, created_at)
+		SELECT gen_random_uuid(), rt.user_id, 'resource_rated', 'rating', rt.id, r.course_id, rt.created_at
+		FROM ratings rt
+		JOIN resources r ON r.id = rt.resource_id
+		WHERE rt.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM activities a WHERE a.object_type = 'rating' AND a.object_id = rt.id AND a.type = 'resource_rated')`,
+
+		`INSERT INTO activities (id, actor_id, type, object_type, object_id, course_id, created_at)
+		SELECT gen_random_uuid(), f.follower_id, 'user_followed', 'user', f.following_id, NULL, f.created_at
+		FROM follows f
+		WHERE NOT EXISTS (SELECT 1 FROM activities a WHERE a.actor_id = f.follower_id AND a.object_type = 'user' AND a.object_id = f.following_id AND a.type = 'user_followed')`,
+	}
+
+	var total int64
+	for _, statement := range statements {
+		result := database.DB.Exec(statement)
+		if result.Error != nil {
+			return total, fmt.Errorf("failed to backfill activities: %w", result.Error)
+		}
+		total += result.RowsAffected
+	}
+
+	return total, nil
+}
+
+func isActivityType(activityType models.ActivityType) bool {
+	for _, t := range models.ActivityTypes {
+		if t == activityType {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeFeedCursor builds an opaque cursor pointing just after an activity
+func encodeFeedCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := strconv.FormatInt(createdAt.UnixMicro(), 10) + "_" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeFeedCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+
+	micros, rawID, found := strings.Cut(string(raw), "_")
+	if !found {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+
+	unixMicro, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return time.Time{}, uuid.Nil, ErrInvalidCursor
+	}
+
+	return time.UnixMicro(unixMicro), id, nil
+}
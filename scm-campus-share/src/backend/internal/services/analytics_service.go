@@ -0,0 +1,438 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// Analytics ranges
+const (
+	// DefaultAnalyticsDays is the range analytics cover when none is given
+	DefaultAnalyticsDays = 30
+	// MaxAnalyticsDays caps how long an analytics range can be
+	MaxAnalyticsDays = 366
+	// audienceLimit caps how many universities or departments are listed
+	audienceLimit = 20
+)
+
+// AnalyticsService reports how resources and the platform are used
+type AnalyticsService struct{}
+
+// NewAnalyticsService creates a new analytics service
+func NewAnalyticsService() *AnalyticsService {
+	return &AnalyticsService{}
+}
+
+// DateRange is a range of whole days in UTC, both ends included
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// normalize fills in the default range and checks its bounds
+func (r *DateRange) normalize() error {
+	if r.To.IsZero() {
+		r.To = time.Now().UTC()
+	}
+	r.To = truncateToDay(r.To)
+
+	if r.From.IsZero() {
+		r.From = r.To.AddDate(0, 0, -(DefaultAnalyticsDays - 1))
+	}
+	r.From = truncateToDay(r.From)
+
+	if r.From.After(r.To) || r.To.Sub(r.From) >= MaxAnalyticsDays*24*time.Hour {
+		return ErrInvalidDateRange
+	}
+
+	return nil
+}
+
+// days returns every day in the range
+func (r *DateRange) days() []string {
+	var days []string
+	for day := r.From; !day.After(r.To); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format("2006-01-02"))
+	}
+	return days
+}
+
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// DailyEngagement is the number of views and downloads on one day
+type DailyEngagement struct {
+	Date      string `json:"date"`
+	Views     int64  `json:"views"`
+	Downloads int64  `json:"downloads"`
+}
+
+// RatingCount is the number of ratings with a given number of stars
+type RatingCount struct {
+	Stars int   `json:"stars"`
+	Count int64 `json:"count"`
+}
+
+// SourceCount is the number of views and downloads that came from a source
+type SourceCount struct {
+	Source    models.EngagementSource `json:"source"`
+	Views     int64                   `json:"views"`
+	Downloads int64                   `json:"downloads"`
+}
+
+// AudienceCount is the number of views and downloads by members of a university
+// or department. ID is nil for anonymous viewers and users without one.
+type AudienceCount struct {
+	ID        *uuid.UUID `json:"id"`
+	Name      string     `json:"name"`
+	Views     int64      `json:"views"`
+	Downloads int64      `json:"downloads"`
+}
+
+// ResourceAnalytics describes how a resource was used over a date range
+type ResourceAnalytics struct {
+	ResourceID         uuid.UUID         `json:"resource_id"`
+	From               string            `json:"from"`
+	To                 string            `json:"to"`
+	Views              int64             `json:"views"`
+	Downloads          int64             `json:"downloads"`
+	UniqueUsers        int64             `json:"unique_users"` // Signed-in users who viewed or downloaded
+	Daily              []DailyEngagement `json:"daily"`
+	AverageRating      float64           `json:"average_rating"`
+	RatingDistribution []RatingCount     `json:"rating_distribution"` // All ratings, not only those in the range
+	Sources            []SourceCount     `json:"sources"`
+	Universities       []AudienceCount   `json:"universities"`
+	Departments        []AudienceCount   `json:"departments"`
+}
+
+// GetResourceAnalytics reports views and downloads of a resource per day, by source
+// and by audience, along with its ratings. Only the owner and moderators may see them.
+func (s *AnalyticsService) GetResourceAnalytics(resourceID, userID uuid.UUID, isModerator bool, dateRange DateRange) (*ResourceAnalytics, error) {
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+
+	if resource.UserID != userID && !isModerator {
+		return nil, ErrUnauthorized
+	}
+
+	if err := dateRange.normalize(); err != nil {
+		return nil, err
+	}
+
+	analytics := &ResourceAnalytics{
+		ResourceID: resourceID,
+		From:       dateRange.From.Format("2006-01-02"),
+		To:         dateRange.To.Format("2006-01-02"),
+	}
+
+	// Views and downloads of the resource in the range
+	events := func() *gorm.DB {
+		return database.DB.Table("engagement_events e").
+			Where("e.entity_type = ? AND e.entity_id = ? AND e.type IN ?", "resource", resourceID,
+				[]models.EngagementType{models.EngagementTypeView, models.EngagementTypeDownload}).
+			Where("e.created_at >= ? AND e.created_at < ?", dateRange.From, dateRange.To.AddDate(0, 0, 1))
+	}
+	counts := "COUNT(*) FILTER (WHERE e.type = 'view') AS views, COUNT(*) FILTER (WHERE e.type = 'download') AS downloads"
+
+	var totals struct {
+		Views       int64
+		Downloads   int64
+		UniqueUsers int64
+	}
+	if err := events().Select(counts + ", COUNT(DISTINCT e.user_id) AS unique_users").Scan(&totals).Error; err != nil {
+		return nil, fmt.Errorf("failed to count engagement: %w", err)
+	}
+	analytics.Views = totals.Views
+	analytics.Downloads = totals.Downloads
+	analytics.UniqueUsers = totals.UniqueUsers
+
+	var daily []DailyEngagement
+	if err := events().
+		Select("TO_CHAR(e.created_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS date, " + counts).
+		Group("date").
+		Scan(&daily).Error; err != nil {
+		return nil, fmt.Errorf("failed to get daily engagement: %w", err)
+	}
+	analytics.Daily = fillDays(dateRange, daily)
+
+	// Events recorded before sources were tracked count as direct
+	source := fmt.Sprintf("COALESCE(NULLIF(e.source, ''), '%s')", models.EngagementSourceDirect)
+	if err := events().
+		Select(source + " AS source, " + counts).
+		Group(source).
+		Order("views DESC, downloads DESC").
+		Scan(&analytics.Sources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get engagement sources: %w", err)
+	}
+
+	if err := events().
+		Select("u.university_id AS id, COALESCE(un.name, '') AS name, " + counts).
+		Joins("LEFT JOIN users u ON u.id = e.user_id").
+		Joins("LEFT JOIN universities un ON un.id = u.university_id").
+		Group("u.university_id, un.name").
+		Order("views DESC, downloads DESC").
+		Limit(audienceLimit).
+		Scan(&analytics.Universities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get audience by university: %w", err)
+	}
+
+	if err := events().
+		Select("u.department_id AS id, COALESCE(d.name, '') AS name, " + counts).
+		Joins("LEFT JOIN users u ON u.id = e.user_id").
+		Joins("LEFT JOIN departments d ON d.id = u.department_id").
+		Group("u.department_id, d.name").
+		Order("views DESC, downloads DESC").
+		Limit(audienceLimit).
+		Scan(&analytics.Departments).Error; err != nil {
+		return nil, fmt.Errorf("failed to get audience by department: %w", err)
+	}
+
+	var ratings []RatingCount
+	if err := database.DB.Model(&models.Rating{}).
+		Select("value AS stars, COUNT(*) AS count").
+		Where("resource_id = ?", resourceID).
+		Group("value").
+		Scan(&ratings).Error; err != nil {
+		return nil, fmt.Errorf("failed to get rating distribution: %w", err)
+	}
+	analytics.RatingDistribution, analytics.AverageRating = ratingDistribution(ratings)
+
+	return analytics, nil
+}
+
+// fillDays returns one entry per day in the range, with zeros for days without engagement
+func fillDays(dateRange DateRange, daily []DailyEngagement) []DailyEngagement {
+	byDate := make(map[string]DailyEngagement, len(daily))
+	for _, day := range daily {
+		byDate[day.Date] = day
+	}
+
+	days := dateRange.days()
+	filled := make([]DailyEngagement, len(days))
+	for i, date := range days {
+		filled[i] = byDate[date]
+		filled[i].Date = date
+	}
+	return filled
+}
+
+// ratingDistribution lists the count for every star from 1 to 5 and the average
+func ratingDistribution(ratings []RatingCount) ([]RatingCount, float64) {
+	distribution := make([]RatingCount, 5)
+	for i := range distribution {
+		distribution[i].Stars = i + 1
+	}
+
+	var total, sum int64
+	for _, rating := range ratings {
+		if rating.Stars < 1 || rating.Stars > 5 {
+			continue
+		}
+		distribution[rating.Stars-1].Count = rating.Count
+		total += rating.Count
+		sum += rating.Count * int64(rating.Stars)
+	}
+
+	if total == 0 {
+		return distribution, 0
+	}
+	return distribution, float64(sum) / float64(total)
+}
+
+// activityTables are the tables whose rows show a signed-in user was active
+var activityTables = []string{"engagement_events", "resources", "comments", "ratings", "forum_topics", "forum_replies"}
+
+// rollupRow is one university's share of a metric. UniversityID is nil for
+// anonymous visitors and users without a university.
+type rollupRow struct {
+	UniversityID *uuid.UUID
+	models.PlatformMetrics
+	ResolutionHours float64
+}
+
+// rollupQuery counts one or more metrics per university
+type rollupQuery struct {
+	name string
+	sql  string
+	args []interface{}
+}
+
+// RollupDay computes the platform metrics for one day, for each university and
+// for the platform as a whole, replacing any earlier rollup of that day. It is
+// meant to run nightly, for the day that just ended.
+func (s *AnalyticsService) RollupDay(day time.Time) (int, error) {
+	start := truncateToDay(day)
+	end := start.AddDate(0, 0, 1)
+
+	queries := []rollupQuery{
+		{"signups", `
+			SELECT university_id, COUNT(*) AS signups
+			FROM users
+			WHERE created_at >= ? AND created_at < ?
+			GROUP BY university_id`, []interface{}{start, end}},
+		{"uploads", `
+			SELECT u.university_id, COUNT(*) AS uploads
+			FROM resources r JOIN users u ON u.id = r.user_id
+			WHERE r.created_at >= ? AND r.created_at < ?
+			GROUP BY u.university_id`, []interface{}{start, end}},
+		// Views and downloads of resources; anonymous ones only count for the platform
+		{"views and downloads", `
+			SELECT u.university_id,
+			       COUNT(*) FILTER (WHERE e.type = 'view') AS views,
+			       COUNT(*) FILTER (WHERE e.type = 'download') AS downloads
+			FROM engagement_events e LEFT JOIN users u ON u.id = e.user_id
+			WHERE e.entity_type = 'resource' AND e.type IN ('view', 'download')
+			  AND e.created_at >= ? AND e.created_at < ?
+			GROUP BY u.university_id`, []interface{}{start, end}},
+		{"reports opened", `
+			SELECT u.university_id, COUNT(*) AS reports_opened
+			FROM reports r JOIN users u ON u.id = r.user_id
+			WHERE r.created_at >= ? AND r.created_at < ?
+			GROUP BY u.university_id`, []interface{}{start, end}},
+		{"reports resolved", `
+			SELECT u.university_id, COUNT(*) AS reports_resolved,
+			       SUM(EXTRACT(EPOCH FROM r.reviewed_at - r.created_at)) / 3600 AS resolution_hours
+			FROM reports r JOIN users u ON u.id = r.user_id
+			WHERE r.status IN ('approved', 'rejected')
+			  AND r.reviewed_at >= ? AND r.reviewed_at < ?
+			GROUP BY u.university_id`, []interface{}{start, end}},
+	}
+
+	// Active users over the day and the 7 and 30 days ending with it. Every
+	// user belongs to at most one university, so the counts add up to the
+	// platform's.
+	monthStart := start.AddDate(0, 0, -29)
+	parts := make([]string, len(activityTables))
+	var activityArgs []interface{}
+	for i, table := range activityTables {
+		parts[i] = "SELECT user_id, created_at FROM " + table + " WHERE user_id IS NOT NULL AND created_at >= ? AND created_at < ?"
+		activityArgs = append(activityArgs, monthStart, end)
+	}
+	queries = append(queries, rollupQuery{"active users", `
+		SELECT u.university_id,
+		       COUNT(DISTINCT a.user_id) FILTER (WHERE a.created_at >= ?) AS daily_active_users,
+		       COUNT(DISTINCT a.user_id) FILTER (WHERE a.created_at >= ?) AS weekly_active_users,
+		       COUNT(DISTINCT a.user_id) AS monthly_active_users
+		FROM (` + strings.Join(parts, " UNION ALL ") + `) a
+		JOIN users u ON u.id = a.user_id
+		GROUP BY u.university_id`, append([]interface{}{start, start.AddDate(0, 0, -6)}, activityArgs...)})
+
+	// The platform's totals are kept under the nil university
+	totals := map[uuid.UUID]*rollupRow{uuid.Nil: {}}
+	for _, query := range queries {
+		var rows []rollupRow
+		if err := database.DB.Raw(query.sql, query.args...).Scan(&rows).Error; err != nil {
+			return 0, fmt.Errorf("failed to count %s: %w", query.name, err)
+		}
+
+		for _, row := range rows {
+			addRollup(totals[uuid.Nil], row)
+			if row.UniversityID == nil {
+				continue
+			}
+			if totals[*row.UniversityID] == nil {
+				totals[*row.UniversityID] = &rollupRow{}
+			}
+			addRollup(totals[*row.UniversityID], row)
+		}
+	}
+
+	now := time.Now()
+	metrics := make([]models.DailyMetric, 0, len(totals))
+	for universityID, total := range totals {
+		if total.ReportsResolved > 0 {
+			total.AvgResolutionHours = total.ResolutionHours / float64(total.ReportsResolved)
+		}
+		metrics = append(metrics, models.DailyMetric{
+			Date:            start,
+			UniversityID:    universityID,
+			PlatformMetrics: total.PlatformMetrics,
+			ComputedAt:      now,
+		})
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("date = ?", start).Delete(&models.DailyMetric{}).Error; err != nil {
+			return fmt.Errorf("failed to clear daily metrics: %w", err)
+		}
+		if err := tx.Create(&metrics).Error; err != nil {
+			return fmt.Errorf("failed to store daily metrics: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(metrics), nil
+}
+
+func addRollup(total *rollupRow, row rollupRow) {
+	total.Signups += row.Signups
+	total.Uploads += row.Uploads
+	total.Views += row.Views
+	total.Downloads += row.Downloads
+	total.DailyActiveUsers += row.DailyActiveUsers
+	total.WeeklyActiveUsers += row.WeeklyActiveUsers
+	total.MonthlyActiveUsers += row.MonthlyActiveUsers
+	total.ReportsOpened += row.ReportsOpened
+	total.ReportsResolved += row.ReportsResolved
+	total.ResolutionHours += row.ResolutionHours
+}
+
+// PlatformDay is the platform metrics for one day
+type PlatformDay struct {
+	Date string `json:"date"`
+	models.PlatformMetrics
+}
+
+// GetPlatformAnalytics returns the rolled-up platform metrics for each day in
+// the range, for one university or, when universityID is nil, the whole
+// platform. Days that have not been rolled up yet are all zeros.
+func (s *AnalyticsService) GetPlatformAnalytics(dateRange DateRange, universityID *uuid.UUID) ([]PlatformDay, error) {
+	if err := dateRange.normalize(); err != nil {
+		return nil, err
+	}
+
+	university := uuid.Nil
+	if universityID != nil {
+		university = *universityID
+	}
+
+	var metrics []models.DailyMetric
+	if err := database.DB.
+		Where("university_id = ? AND date >= ? AND date <= ?", university, dateRange.From, dateRange.To).
+		Find(&metrics).Error; err != nil {
+		return nil, fmt.Errorf("failed to get daily metrics: %w", err)
+	}
+
+	byDate := make(map[string]models.PlatformMetrics, len(metrics))
+	for _, metric := range metrics {
+		byDate[metric.Date.UTC().Format("2006-01-02")] = metric.PlatformMetrics
+	}
+
+	days := dateRange.days()
+	series := make([]PlatformDay, len(days))
+	for i, date := range days {
+		series[i] = PlatformDay{Date: date, PlatformMetrics: byDate[date]}
+	}
+
+	return series, nil
+}
@@ -19,14 +19,19 @@ var (
 	ErrUserExists        = errors.New("user already exists")
 	ErrEmailRequired     = errors.New("email is required")
 	ErrPasswordRequired  = errors.New("password is required")
+	ErrAccountInactive   = errors.New("account is inactive")
 )
 
 // AuthService handles authentication-related operations
-type AuthService struct{}
+type AuthService struct {
+	banService *BanService
+}
 
 // NewAuthService creates a new auth service
 func NewAuthService() *AuthService {
-	return &AuthService{}
+	return &AuthService{
+		banService: NewBanService(),
+	}
 }
 
 // RegisterRequest represents a user registration request
@@ -92,23 +97,17 @@ func (s *AuthService) Register(req RegisterRequest, jwtSecret string, expiration
 
 // Login authenticates a user and returns a JWT token
 func (s *AuthService) Login(req LoginRequest, jwtSecret string, expirationHours int) (*models.User, string, error) {
-	// Find user
-	var user models.User
-	if err := database.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, "", ErrInvalidCredentials
-		}
-		return nil, "", fmt.Errorf("failed to find user: %w", err)
+	user, err := s.VerifyCredentials(req.Email, req.Password)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Check if user is active and not banned
-	if !user.IsActive || user.IsBanned {
-		return nil, "", errors.New("account is inactive or banned")
+	// Check if user is active and not banned; expired suspensions are lifted here
+	if !user.IsActive {
+		return nil, "", ErrAccountInactive
 	}
-
-	// Verify password
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(req.Password)); err != nil {
-		return nil, "", ErrInvalidCredentials
+	if err := s.banService.CheckBan(user); err != nil {
+		return nil, "", err
 	}
 
 	// Generate JWT token
@@ -117,7 +116,24 @@ func (s *AuthService) Login(req LoginRequest, jwtSecret string, expirationHours
 		return nil, "", fmt.Errorf("failed to generate token: %w", err)
 	}
 
-	return &user, token, nil
+	return user, token, nil
+}
+
+// VerifyCredentials checks an email and password pair without issuing a token
+func (s *AuthService) VerifyCredentials(email, password string) (*models.User, error) {
+	var user models.User
+	if err := database.DB.Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &user, nil
 }
 
 // GetUserByID retrieves a user by ID
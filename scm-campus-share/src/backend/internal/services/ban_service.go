@@ -0,0 +1,370 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var (
+	ErrBanNotFound          = errors.New("no active ban for this user")
+	ErrAppealNotFound       = errors.New("appeal not found")
+	ErrAppealExists         = errors.New("an appeal has already been submitted for this ban")
+	ErrAppealAlreadyHandled = errors.New("appeal has already been reviewed")
+	ErrInvalidBanDuration   = errors.New("ban duration must not be negative")
+)
+
+// BannedError is returned when a suspended user tries to log in or use a token.
+// It carries the details the client needs to explain the suspension.
+type BannedError struct {
+	Reason    string
+	Until     *time.Time
+	CanAppeal bool
+}
+
+func (e *BannedError) Error() string {
+	if e.Until == nil {
+		return fmt.Sprintf("account is banned: %s", e.Reason)
+	}
+	return fmt.Sprintf("account is suspended until %s: %s", e.Until.UTC().Format(time.RFC1123), e.Reason)
+}
+
+// BanService handles user suspensions and ban appeals
+type BanService struct{}
+
+// NewBanService creates a new ban service
+func NewBanService() *BanService {
+	return &BanService{}
+}
+
+// BanUserRequest represents a request to ban a user
+type BanUserRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	// DurationHours limits the suspension; zero or omitted means permanent
+	DurationHours int `json:"duration_hours,omitempty"`
+}
+
+// BanUser suspends a user, replacing any ban that is currently active
+func (s *BanService) BanUser(userID, adminID uuid.UUID, req BanUserRequest) (*models.UserBan, error) {
+	if req.DurationHours < 0 {
+		return nil, ErrInvalidBanDuration
+	}
+
+	var user models.User
+	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	ban := models.UserBan{
+		UserID:   userID,
+		BannedBy: &adminID,
+		Reason:   req.Reason,
+	}
+	if req.DurationHours > 0 {
+		expiresAt := time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+		ban.ExpiresAt = &expiresAt
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.liftBans(tx, userID, &adminID); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&ban).Error; err != nil {
+			return fmt.Errorf("failed to create ban: %w", err)
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"is_banned":    true,
+			"ban_reason":   ban.Reason,
+			"banned_until": ban.ExpiresAt,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to ban user: %w", err)
+	}
+
+	return &ban, nil
+}
+
+// UnbanUser lifts the active ban on a user
+func (s *BanService) UnbanUser(userID, adminID uuid.UUID) (*models.User, error) {
+	var user models.User
+	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return s.liftBans(tx, userID, &adminID)
+	}); err != nil {
+		return nil, fmt.Errorf("failed to unban user: %w", err)
+	}
+
+	user.IsBanned = false
+	user.BanReason = ""
+	user.BannedUntil = nil
+
+	return &user, nil
+}
+
+// CheckBan returns a *BannedError if the user is currently suspended.
+// Suspensions that have passed their expiry are lifted as a side effect.
+func (s *BanService) CheckBan(user *models.User) error {
+	if !user.IsBanned {
+		return nil
+	}
+
+	if user.BannedUntil != nil && time.Now().After(*user.BannedUntil) {
+		if err := database.DB.Transaction(func(tx *gorm.DB) error {
+			return s.liftBans(tx, user.ID, nil)
+		}); err != nil {
+			return fmt.Errorf("failed to lift expired ban: %w", err)
+		}
+
+		user.IsBanned = false
+		user.BanReason = ""
+		user.BannedUntil = nil
+		return nil
+	}
+
+	banErr := &BannedError{
+		Reason: user.BanReason,
+		Until:  user.BannedUntil,
+	}
+
+	if ban, err := s.GetActiveBan(user.ID); err == nil {
+		banErr.CanAppeal = ban.Appeal == nil
+	}
+
+	return banErr
+}
+
+// CheckUserBan loads a user and checks whether they are currently suspended
+func (s *BanService) CheckUserBan(userID uuid.UUID) error {
+	var user models.User
+	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.CheckBan(&user)
+}
+
+// GetActiveBan returns the ban currently in force for a user
+func (s *BanService) GetActiveBan(userID uuid.UUID) (*models.UserBan, error) {
+	var ban models.UserBan
+	if err := database.DB.
+		Preload("Appeal").
+		Where("user_id = ? AND lifted_at IS NULL", userID).
+		Order("created_at DESC").
+		First(&ban).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBanNotFound
+		}
+		return nil, fmt.Errorf("failed to get ban: %w", err)
+	}
+
+	return &ban, nil
+}
+
+// ListUserBans returns the full ban history of a user, newest first
+func (s *BanService) ListUserBans(userID uuid.UUID) ([]models.UserBan, error) {
+	var bans []models.UserBan
+	if err := database.DB.
+		Preload("Appeal").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&bans).Error; err != nil {
+		return nil, fmt.Errorf("failed to list bans: %w", err)
+	}
+
+	return bans, nil
+}
+
+// CreateAppealRequest represents a banned user's appeal.
+// Banned users cannot obtain a token, so the appeal carries their credentials.
+type CreateAppealRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	Message  string `json:"message" binding:"required"`
+}
+
+// CreateAppeal files an appeal against the user's active ban
+func (s *BanService) CreateAppeal(userID uuid.UUID, message string) (*models.BanAppeal, error) {
+	// Lifts the ban first if it has already expired
+	var banErr *BannedError
+	if err := s.CheckUserBan(userID); err == nil {
+		return nil, ErrBanNotFound
+	} else if !errors.As(err, &banErr) {
+		return nil, err
+	}
+
+	ban, err := s.GetActiveBan(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ban.Appeal != nil {
+		return nil, ErrAppealExists
+	}
+
+	appeal := models.BanAppeal{
+		BanID:   ban.ID,
+		UserID:  userID,
+		Message: message,
+		Status:  models.AppealStatusPending,
+	}
+
+	// A concurrent appeal against the same ban may have been created meanwhile
+	if err := database.DB.Create(&appeal).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrAppealExists
+		}
+		return nil, fmt.Errorf("failed to create appeal: %w", err)
+	}
+
+	return s.GetAppealByID(appeal.ID)
+}
+
+// GetAppealByID retrieves an appeal by ID
+func (s *BanService) GetAppealByID(appealID uuid.UUID) (*models.BanAppeal, error) {
+	var appeal models.BanAppeal
+	if err := database.DB.
+		Preload("Ban").
+		Preload("User").
+		Where("id = ?", appealID).
+		First(&appeal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAppealNotFound
+		}
+		return nil, fmt.Errorf("failed to get appeal: %w", err)
+	}
+
+	return &appeal, nil
+}
+
+// ListAppealsRequest represents a request to list ban appeals
+type ListAppealsRequest struct {
+	Page     int                 `form:"page"`
+	PageSize int                 `form:"page_size"`
+	Status   models.AppealStatus `form:"status"`
+}
+
+// ListAppeals lists ban appeals for the moderation queue
+func (s *BanService) ListAppeals(req ListAppealsRequest) ([]models.BanAppeal, int64, error) {
+	query := database.DB.Model(&models.BanAppeal{}).
+		Preload("Ban").
+		Preload("User")
+
+	if req.Status != "" {
+		query = query.Where("status = ?", req.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count appeals: %w", err)
+	}
+
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	offset := (req.Page - 1) * req.PageSize
+
+	var appeals []models.BanAppeal
+	if err := query.Order("created_at ASC").Offset(offset).Limit(req.PageSize).Find(&appeals).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list appeals: %w", err)
+	}
+
+	return appeals, total, nil
+}
+
+// ReviewAppealRequest represents a moderator's decision on an appeal
+type ReviewAppealRequest struct {
+	AdminNotes string `json:"admin_notes,omitempty"`
+}
+
+// ApproveAppeal accepts an appeal and lifts the ban it was filed against
+func (s *BanService) ApproveAppeal(appealID, adminID uuid.UUID, req ReviewAppealRequest) (*models.BanAppeal, error) {
+	return s.reviewAppeal(appealID, adminID, req, models.AppealStatusApproved)
+}
+
+// RejectAppeal rejects an appeal, leaving the ban in place
+func (s *BanService) RejectAppeal(appealID, adminID uuid.UUID, req ReviewAppealRequest) (*models.BanAppeal, error) {
+	return s.reviewAppeal(appealID, adminID, req, models.AppealStatusRejected)
+}
+
+func (s *BanService) reviewAppeal(appealID, adminID uuid.UUID, req ReviewAppealRequest, status models.AppealStatus) (*models.BanAppeal, error) {
+	var appeal models.BanAppeal
+	if err := database.DB.Where("id = ?", appealID).First(&appeal).Error; err != nil {
+		return nil, ErrAppealNotFound
+	}
+
+	if appeal.Status != models.AppealStatusPending {
+		return nil, ErrAppealAlreadyHandled
+	}
+
+	now := time.Now()
+	appeal.Status = status
+	appeal.ReviewedBy = &adminID
+	appeal.ReviewedAt = &now
+	appeal.AdminNotes = req.AdminNotes
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&appeal).Error; err != nil {
+			return err
+		}
+
+		if status != models.AppealStatusApproved {
+			return nil
+		}
+
+		// Only lift the ban the appeal was filed against, not a newer one
+		var ban models.UserBan
+		if err := tx.Where("id = ?", appeal.BanID).First(&ban).Error; err != nil {
+			return err
+		}
+		if ban.LiftedAt != nil {
+			return nil
+		}
+		return s.liftBans(tx, appeal.UserID, &adminID)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review appeal: %w", err)
+	}
+
+	return s.GetAppealByID(appealID)
+}
+
+// liftBans closes every open ban for a user and clears the ban flags on the account.
+// liftedBy is nil when the ban expired on its own.
+func (s *BanService) liftBans(tx *gorm.DB, userID uuid.UUID, liftedBy *uuid.UUID) error {
+	if err := tx.Model(&models.UserBan{}).
+		Where("user_id = ? AND lifted_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"lifted_at": time.Now(),
+			"lifted_by": liftedBy,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to lift bans: %w", err)
+	}
+
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"is_banned":    false,
+		"ban_reason":   "",
+		"banned_until": nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to clear ban status: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var (
+	ErrFolderNotFound     = errors.New("folder not found")
+	ErrFolderNameRequired = errors.New("folder name is required")
+	ErrFolderNameTaken    = errors.New("you already have a folder with this name")
+)
+
+// getBookmarkFolder gets one of the user's bookmark folders
+func getBookmarkFolder(folderID, userID uuid.UUID) (*models.BookmarkFolder, error) {
+	var folder models.BookmarkFolder
+	if err := database.DB.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFolderNotFound
+		}
+		return nil, fmt.Errorf("failed to get folder: %w", err)
+	}
+	return &folder, nil
+}
+
+// checkFolderName trims a folder name and checks the user has no other folder
+// with the same name
+func checkFolderName(name string, userID uuid.UUID, folderID *uuid.UUID) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", ErrFolderNameRequired
+	}
+
+	query := database.DB.Model(&models.BookmarkFolder{}).
+		Where("user_id = ? AND LOWER(name) = LOWER(?)", userID, name)
+	if folderID != nil {
+		query = query.Where("id <> ?", folderID)
+	}
+
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return "", fmt.Errorf("failed to check folder name: %w", err)
+	}
+	if count > 0 {
+		return "", ErrFolderNameTaken
+	}
+
+	return name, nil
+}
+
+// ListFolders lists a user's bookmark folders by name with how many bookmarks
+// are in each, and how many bookmarks are in no folder
+func (s *BookmarkService) ListFolders(userID uuid.UUID) ([]models.BookmarkFolder, int64, error) {
+	folders := []models.BookmarkFolder{}
+	if err := database.DB.Where("user_id = ?", userID).Order("LOWER(name)").Find(&folders).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list folders: %w", err)
+	}
+
+	var counts []struct {
+		FolderID *uuid.UUID
+		Count    int64
+	}
+	if err := database.DB.Model(&models.Bookmark{}).
+		Select("bookmarks.folder_id, COUNT(*) AS count").
+		Joins("JOIN resources ON resources.id = bookmarks.resource_id AND resources.deleted_at IS NULL").
+		Where("bookmarks.user_id = ?", userID).
+		Group("bookmarks.folder_id").
+		Scan(&counts).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count bookmarks: %w", err)
+	}
+
+	var unfiled int64
+	byFolder := make(map[uuid.UUID]int64, len(counts))
+	for _, count := range counts {
+		if count.FolderID == nil {
+			unfiled = count.Count
+			continue
+		}
+		byFolder[*count.FolderID] = count.Count
+	}
+	for i := range folders {
+		folders[i].BookmarkCount = byFolder[folders[i].ID]
+	}
+
+	return folders, unfiled, nil
+}
+
+// CreateFolder creates a bookmark folder
+func (s *BookmarkService) CreateFolder(userID uuid.UUID, name string) (*models.BookmarkFolder, error) {
+	name, err := checkFolderName(name, userID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	folder := models.BookmarkFolder{
+		UserID: userID,
+		Name:   name,
+	}
+	if err := database.DB.Create(&folder).Error; err != nil {
+		return nil, fmt.Errorf("failed to create folder: %w", err)
+	}
+
+	return &folder, nil
+}
+
+// RenameFolder renames one of the user's bookmark folders
+func (s *BookmarkService) RenameFolder(folderID, userID uuid.UUID, name string) (*models.BookmarkFolder, error) {
+	folder, err := getBookmarkFolder(folderID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err = checkFolderName(name, userID, &folder.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Model(folder).Update("name", name).Error; err != nil {
+		return nil, fmt.Errorf("failed to rename folder: %w", err)
+	}
+
+	return folder, nil
+}
+
+// DeleteFolder deletes one of the user's bookmark folders. The bookmarks in it
+// are kept and end up in no folder.
+func (s *BookmarkService) DeleteFolder(folderID, userID uuid.UUID) error {
+	folder, err := getBookmarkFolder(folderID, userID)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Bookmark{}).
+			Where("folder_id = ?", folder.ID).
+			Update("folder_id", nil).Error; err != nil {
+			return fmt.Errorf("failed to unfile bookmarks: %w", err)
+		}
+
+		if err := tx.Delete(folder).Error; err != nil {
+			return fmt.Errorf("failed to delete folder: %w", err)
+		}
+		return nil
+	})
+}
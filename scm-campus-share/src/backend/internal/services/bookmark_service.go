@@ -11,16 +11,39 @@ import (
 	"github.com/campus-share/backend/internal/models"
 )
 
+var (
+	ErrBookmarkNotFound  = errors.New("bookmark not found")
+	ErrInvalidBulkAction = errors.New("action must be add, remove or move")
+	ErrNoBulkResources   = errors.New("no resources given")
+	ErrTooManyBookmarks  = errors.New("at most 100 resources can be changed at once")
+)
+
+// maxBulkBookmarks caps how many resources a bulk bookmark request can change
+const maxBulkBookmarks = 100
+
 // BookmarkService handles bookmark-related operations
-type BookmarkService struct{}
+type BookmarkService struct {
+	engagementService *EngagementService
+}
 
 // NewBookmarkService creates a new bookmark service
 func NewBookmarkService() *BookmarkService {
-	return &BookmarkService{}
+	return &BookmarkService{
+		engagementService: NewEngagementService(),
+	}
+}
+
+// BookmarkRequest represents a request to bookmark a resource
+type BookmarkRequest struct {
+	ResourceID uuid.UUID  `json:"resource_id" binding:"required"`
+	FolderID   *uuid.UUID `json:"folder_id"`
+	Note       string     `json:"note"`
 }
 
-// CreateBookmark creates a bookmark
-func (s *BookmarkService) CreateBookmark(resourceID, userID uuid.UUID) (*models.Bookmark, error) {
+// CreateBookmark creates a bookmark, optionally filed in a folder and with a note
+func (s *BookmarkService) CreateBookmark(userID uuid.UUID, req BookmarkRequest) (*models.Bookmark, error) {
+	resourceID := req.ResourceID
+
 	// Verify resource exists
 	var resource models.Resource
 	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
@@ -33,15 +56,27 @@ func (s *BookmarkService) CreateBookmark(resourceID, userID uuid.UUID) (*models.
 		return &existingBookmark, nil // Already bookmarked
 	}
 
+	if req.FolderID != nil {
+		if _, err := getBookmarkFolder(*req.FolderID, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	bookmark := models.Bookmark{
 		ResourceID: resourceID,
 		UserID:     userID,
+		FolderID:   req.FolderID,
+		Note:       req.Note,
 	}
 
 	if err := database.DB.Create(&bookmark).Error; err != nil {
 		return nil, fmt.Errorf("failed to create bookmark: %w", err)
 	}
 
+	if err := s.engagementService.Record("resource", resourceID, &userID, models.EngagementTypeBookmark, 1); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	return s.GetBookmarkByID(bookmark.ID)
 }
 
@@ -53,10 +88,11 @@ func (s *BookmarkService) GetBookmarkByID(bookmarkID uuid.UUID) (*models.Bookmar
 		Preload("Resource.University").
 		Preload("Resource.Department").
 		Preload("Resource.Course").
+		Preload("Folder").
 		Where("id = ?", bookmarkID).
 		First(&bookmark).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("bookmark not found")
+			return nil, ErrBookmarkNotFound
 		}
 		return nil, fmt.Errorf("failed to get bookmark: %w", err)
 	}
@@ -64,22 +100,48 @@ func (s *BookmarkService) GetBookmarkByID(bookmarkID uuid.UUID) (*models.Bookmar
 	return &bookmark, nil
 }
 
-// ListBookmarks lists bookmarks for a user
-func (s *BookmarkService) ListBookmarks(userID uuid.UUID, page, pageSize int) ([]models.Bookmark, int64, error) {
-	if pageSize <= 0 {
-		pageSize = 20
+// ListBookmarksRequest represents a request to list a user's bookmarks
+type ListBookmarksRequest struct {
+	FolderID *uuid.UUID // Only bookmarks in this folder
+	Unfiled  bool       // Only bookmarks in no folder
+	CourseID *uuid.UUID
+	Type     string
+	Search   string // Matches the resource title and the note
+	Page     int
+	PageSize int
+}
+
+// ListBookmarks lists bookmarks for a user. Bookmarks of deleted resources are
+// left out until the resource is restored.
+func (s *BookmarkService) ListBookmarks(userID uuid.UUID, req ListBookmarksRequest) ([]models.Bookmark, int64, error) {
+	if req.PageSize <= 0 {
+		req.PageSize = 20
 	}
-	if page <= 0 {
-		page = 1
+	if req.Page <= 0 {
+		req.Page = 1
 	}
 
 	query := database.DB.Model(&models.Bookmark{}).
-		Where("user_id = ?", userID).
-		Preload("Resource.User").
-		Preload("Resource.University").
-		Preload("Resource.Department").
-		Preload("Resource.Course").
-		Preload("Resource.Tags.Tag")
+		Joins("JOIN resources ON resources.id = bookmarks.resource_id AND resources.deleted_at IS NULL").
+		Where("bookmarks.user_id = ?", userID)
+
+	if req.Unfiled {
+		query = query.Where("bookmarks.folder_id IS NULL")
+	} else if req.FolderID != nil {
+		query = query.Where("bookmarks.folder_id = ?", req.FolderID)
+	}
+
+	if req.CourseID != nil {
+		query = query.Where("resources.course_id = ?", req.CourseID)
+	}
+
+	if req.Type != "" {
+		query = query.Where("resources.type = ?", req.Type)
+	}
+
+	if req.Search != "" {
+		query = query.Where("resources.title ILIKE ? OR bookmarks.note ILIKE ?", "%"+req.Search+"%", "%"+req.Search+"%")
+	}
 
 	var total int64
 	if err := query.Count(&total).Error; err != nil {
@@ -87,19 +149,68 @@ func (s *BookmarkService) ListBookmarks(userID uuid.UUID, page, pageSize int) ([
 	}
 
 	var bookmarks []models.Bookmark
-	offset := (page - 1) * pageSize
-	if err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&bookmarks).Error; err != nil {
+	offset := (req.Page - 1) * req.PageSize
+	if err := query.
+		Preload("Resource.User").
+		Preload("Resource.University").
+		Preload("Resource.Department").
+		Preload("Resource.Course").
+		Preload("Resource.Tags.Tag").
+		Preload("Folder").
+		Order("bookmarks.created_at DESC").
+		Offset(offset).
+		Limit(req.PageSize).
+		Find(&bookmarks).Error; err != nil {
 		return nil, 0, fmt.Errorf("failed to list bookmarks: %w", err)
 	}
 
+	for i := range bookmarks {
+		bookmarks[i].Resource.IsBookmarked = true
+	}
+
 	return bookmarks, total, nil
 }
 
+// UpdateBookmarkRequest represents a request to update a bookmark. It replaces
+// both the note and the folder; leaving out the folder unfiles the bookmark.
+type UpdateBookmarkRequest struct {
+	FolderID *uuid.UUID `json:"folder_id"`
+	Note     string     `json:"note"`
+}
+
+// UpdateBookmark changes the note and folder of a bookmark
+func (s *BookmarkService) UpdateBookmark(bookmarkID, userID uuid.UUID, req UpdateBookmarkRequest) (*models.Bookmark, error) {
+	var bookmark models.Bookmark
+	if err := database.DB.Where("id = ?", bookmarkID).First(&bookmark).Error; err != nil {
+		return nil, ErrBookmarkNotFound
+	}
+
+	// Check ownership
+	if bookmark.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	if req.FolderID != nil {
+		if _, err := getBookmarkFolder(*req.FolderID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := database.DB.Model(&bookmark).Updates(map[string]interface{}{
+		"folder_id": req.FolderID,
+		"note":      req.Note,
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to update bookmark: %w", err)
+	}
+
+	return s.GetBookmarkByID(bookmark.ID)
+}
+
 // DeleteBookmark deletes a bookmark
 func (s *BookmarkService) DeleteBookmark(bookmarkID, userID uuid.UUID) error {
 	var bookmark models.Bookmark
 	if err := database.DB.Where("id = ?", bookmarkID).First(&bookmark).Error; err != nil {
-		return errors.New("bookmark not found")
+		return ErrBookmarkNotFound
 	}
 
 	// Check ownership
@@ -126,4 +237,146 @@ func (s *BookmarkService) IsBookmarked(resourceID, userID uuid.UUID) (bool, erro
 	return count > 0, nil
 }
 
+// BulkBookmarkRequest represents a change to many bookmarks at once: "add"
+// bookmarks the resources (into the folder, if given), "remove" deletes their
+// bookmarks and "move" files their bookmarks in the folder, or in no folder if
+// it is left out
+type BulkBookmarkRequest struct {
+	Action      string      `json:"action" binding:"required"`
+	ResourceIDs []uuid.UUID `json:"resource_ids" binding:"required"`
+	FolderID    *uuid.UUID  `json:"folder_id"`
+}
+
+// BulkBookmarks adds, removes or moves the bookmarks of many resources and
+// returns how many bookmarks changed. Resources that do not exist or are
+// already bookmarked are skipped when adding.
+func (s *BookmarkService) BulkBookmarks(userID uuid.UUID, req BulkBookmarkRequest) (int64, error) {
+	if len(req.ResourceIDs) == 0 {
+		return 0, ErrNoBulkResources
+	}
+	if len(req.ResourceIDs) > maxBulkBookmarks {
+		return 0, ErrTooManyBookmarks
+	}
+
+	switch req.Action {
+	case "add":
+		return s.bulkAdd(userID, req.ResourceIDs, req.FolderID)
+	case "remove":
+		result := database.DB.
+			Where("user_id = ? AND resource_id IN ?", userID, req.ResourceIDs).
+			Delete(&models.Bookmark{})
+		if result.Error != nil {
+			return 0, fmt.Errorf("failed to delete bookmarks: %w", result.Error)
+		}
+		return result.RowsAffected, nil
+	case "move":
+		if req.FolderID != nil {
+			if _, err := getBookmarkFolder(*req.FolderID, userID); err != nil {
+				return 0, err
+			}
+		}
+		result := database.DB.Model(&models.Bookmark{}).
+			Where("user_id = ? AND resource_id IN ?", userID, req.ResourceIDs).
+			Update("folder_id", req.FolderID)
+		if result.Error != nil {
+			return 0, fmt.Errorf("failed to move bookmarks: %w", result.Error)
+		}
+		return result.RowsAffected, nil
+	default:
+		return 0, ErrInvalidBulkAction
+	}
+}
+
+func (s *BookmarkService) bulkAdd(userID uuid.UUID, resourceIDs []uuid.UUID, folderID *uuid.UUID) (int64, error) {
+	if folderID != nil {
+		if _, err := getBookmarkFolder(*folderID, userID); err != nil {
+			return 0, err
+		}
+	}
+
+	var existing []uuid.UUID
+	if err := database.DB.Model(&models.Resource{}).
+		Where("id IN ?", resourceIDs).
+		Pluck("id", &existing).Error; err != nil {
+		return 0, fmt.Errorf("failed to get resources: %w", err)
+	}
+
+	bookmarked, err := bookmarkedResources(&userID, existing)
+	if err != nil {
+		return 0, err
+	}
+
+	bookmarks := make([]models.Bookmark, 0, len(existing))
+	for _, resourceID := range existing {
+		if bookmarked[resourceID] {
+			continue
+		}
+		// Each resource is bookmarked once even if it is listed twice
+		bookmarked[resourceID] = true
+		bookmarks = append(bookmarks, models.Bookmark{
+			ResourceID: resourceID,
+			UserID:     userID,
+			FolderID:   folderID,
+		})
+	}
+	if len(bookmarks) == 0 {
+		return 0, nil
+	}
+
+	if err := database.DB.Omit("Resource", "Folder").Create(&bookmarks).Error; err != nil {
+		return 0, fmt.Errorf("failed to create bookmarks: %w", err)
+	}
+
+	for _, bookmark := range bookmarks {
+		if err := s.engagementService.Record("resource", bookmark.ResourceID, &userID, models.EngagementTypeBookmark, 1); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+
+	return int64(len(bookmarks)), nil
+}
+
+// bookmarkedResources returns which of the resources the user has bookmarked
+func bookmarkedResources(userID *uuid.UUID, resourceIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	bookmarked := make(map[uuid.UUID]bool)
+	if userID == nil || len(resourceIDs) == 0 {
+		return bookmarked, nil
+	}
+
+	var ids []uuid.UUID
+	if err := database.DB.Model(&models.Bookmark{}).
+		Where("user_id = ? AND resource_id IN ?", userID, resourceIDs).
+		Pluck("resource_id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to check bookmarks: %w", err)
+	}
+
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked, nil
+}
+
+// markBookmarked sets IsBookmarked on the resources the user has bookmarked,
+// so lists do not need a call per resource to show it
+func markBookmarked(resources []models.Resource, userID *uuid.UUID) {
+	if userID == nil || len(resources) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, len(resources))
+	for i := range resources {
+		ids[i] = resources[i].ID
+	}
+
+	bookmarked, err := bookmarkedResources(userID, ids)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+
+	for i := range resources {
+		resources[i].IsBookmarked = bookmarked[resources[i].ID]
+	}
+}
+
 
@@ -0,0 +1,606 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var (
+	ErrCollectionNotFound         = errors.New("collection not found")
+	ErrInvalidVisibility          = errors.New("visibility must be private, link or public")
+	ErrCollectionTitleRequired    = errors.New("collection title is required")
+	ErrAlreadyInCollection        = errors.New("resource is already in this collection")
+	ErrNotInCollection            = errors.New("resource is not in this collection")
+	ErrCollectionFull             = errors.New("collection is full")
+	ErrInvalidOrder               = errors.New("order must list every resource in the collection exactly once")
+	ErrCannotFollowOwnCollection  = errors.New("cannot follow your own collection")
+	ErrAlreadyFollowingCollection = errors.New("already following this collection")
+	ErrNotFollowingCollection     = errors.New("not following this collection")
+)
+
+// maxCollectionItems caps how many resources a collection can hold
+const maxCollectionItems = 500
+
+// CollectionService handles collections, user-created ordered lists of
+// resources. Private collections are only seen by their owner, link-only ones
+// by anyone who has their ID, and public ones are also listed in search and on
+// the owner's profile.
+type CollectionService struct {
+	notificationService *NotificationService
+}
+
+// NewCollectionService creates a new collection service
+func NewCollectionService() *CollectionService {
+	return &CollectionService{
+		notificationService: NewNotificationService(),
+	}
+}
+
+// CollectionRequest represents a request to create a collection
+type CollectionRequest struct {
+	Title       string                      `json:"title" binding:"required"`
+	Description string                      `json:"description"`
+	Visibility  models.CollectionVisibility `json:"visibility"`
+}
+
+// UpdateCollectionRequest represents a request to update a collection
+type UpdateCollectionRequest struct {
+	Title       *string                      `json:"title"`
+	Description *string                      `json:"description"`
+	Visibility  *models.CollectionVisibility `json:"visibility"`
+}
+
+// ListCollectionsRequest represents a search of public collections
+type ListCollectionsRequest struct {
+	Search   string
+	SortBy   string // "popular" or "recent"
+	Page     int
+	PageSize int
+}
+
+func validVisibility(visibility models.CollectionVisibility) bool {
+	switch visibility {
+	case models.CollectionVisibilityPrivate, models.CollectionVisibilityLink, models.CollectionVisibilityPublic:
+		return true
+	}
+	return false
+}
+
+// CreateCollection creates a collection, private unless asked otherwise
+func (s *CollectionService) CreateCollection(userID uuid.UUID, req CollectionRequest) (*models.Collection, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, ErrCollectionTitleRequired
+	}
+	if req.Visibility == "" {
+		req.Visibility = models.CollectionVisibilityPrivate
+	}
+	if !validVisibility(req.Visibility) {
+		return nil, ErrInvalidVisibility
+	}
+
+	collection := models.Collection{
+		UserID:      userID,
+		Title:       title,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+	}
+
+	if err := database.DB.Create(&collection).Error; err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	return s.GetCollection(collection.ID, &userID)
+}
+
+// getOwned gets a collection, checking the user owns it unless they are an admin
+func (s *CollectionService) getOwned(collectionID, userID uuid.UUID, isAdmin bool) (*models.Collection, error) {
+	var collection models.Collection
+	if err := database.DB.Where("id = ?", collectionID).First(&collection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	if collection.UserID != userID && !isAdmin {
+		if collection.Visibility == models.CollectionVisibilityPrivate {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, ErrUnauthorized
+	}
+
+	return &collection, nil
+}
+
+// getVisible gets a collection the user can see
+func (s *CollectionService) getVisible(collectionID uuid.UUID, userID *uuid.UUID) (*models.Collection, error) {
+	var collection models.Collection
+	if err := database.DB.Preload("User").Where("id = ?", collectionID).First(&collection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCollectionNotFound
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+
+	if collection.Visibility == models.CollectionVisibilityPrivate && (userID == nil || *userID != collection.UserID) {
+		return nil, ErrCollectionNotFound
+	}
+
+	return &collection, nil
+}
+
+// visibleItems lists the items of a collection whose resource the user can
+// see, in order: their own resources and approved ones shared with them. Items
+// of deleted or hidden resources are left out but kept, so they come back if
+// the resource does.
+func visibleItems(collectionID uuid.UUID, userID *uuid.UUID) ([]models.CollectionItem, error) {
+	visible := visibleResources(userID)(database.DB.Where("resources.is_approved = ?", true))
+	if userID != nil {
+		visible = database.DB.Where("resources.user_id = ?", *userID).Or(visible)
+	}
+
+	items := []models.CollectionItem{}
+	if err := database.DB.Model(&models.CollectionItem{}).
+		Joins("JOIN resources ON resources.id = collection_items.resource_id AND resources.deleted_at IS NULL").
+		Where("collection_items.collection_id = ?", collectionID).
+		Where(visible).
+		Preload("Resource.User").
+		Preload("Resource.Course").
+		Preload("Resource.Tags.Tag").
+		Order("collection_items.position, collection_items.created_at").
+		Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to list collection items: %w", err)
+	}
+
+	resourceIDs := make([]uuid.UUID, len(items))
+	for i := range items {
+		resourceIDs[i] = items[i].ResourceID
+	}
+	bookmarked, err := bookmarkedResources(userID, resourceIDs)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	for i := range items {
+		items[i].Resource.IsBookmarked = bookmarked[items[i].ResourceID]
+	}
+
+	return items, nil
+}
+
+// GetCollection gets a collection with the resources in it the user can see
+func (s *CollectionService) GetCollection(collectionID uuid.UUID, userID *uuid.UUID) (*models.Collection, error) {
+	collection, err := s.getVisible(collectionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := visibleItems(collection.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	collection.Items = items
+
+	return collection, nil
+}
+
+// UpdateCollection updates a collection's title, description or visibility
+func (s *CollectionService) UpdateCollection(collectionID, userID uuid.UUID, req UpdateCollectionRequest) (*models.Collection, error) {
+	collection, err := s.getOwned(collectionID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if title == "" {
+			return nil, ErrCollectionTitleRequired
+		}
+		updates["title"] = title
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Visibility != nil {
+		if !validVisibility(*req.Visibility) {
+			return nil, ErrInvalidVisibility
+		}
+		updates["visibility"] = *req.Visibility
+	}
+
+	if len(updates) > 0 {
+		if err := database.DB.Model(collection).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update collection: %w", err)
+		}
+	}
+
+	return s.GetCollection(collection.ID, &userID)
+}
+
+// DeleteCollection deletes a collection, checking the user owns it unless
+// they are an admin
+func (s *CollectionService) DeleteCollection(collectionID, userID uuid.UUID, isAdmin bool) error {
+	collection, err := s.getOwned(collectionID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Delete(collection).Error; err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	return nil
+}
+
+// AddItem adds a resource to the end of a collection. The owner can add their
+// own resources and approved ones shared with them.
+func (s *CollectionService) AddItem(collectionID, userID, resourceID uuid.UUID) (*models.CollectionItem, error) {
+	collection, err := s.getOwned(collectionID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		return nil, ErrResourceNotFound
+	}
+	if err := checkVisible(&resource, &userID, false); err != nil {
+		return nil, err
+	}
+
+	item := models.CollectionItem{
+		CollectionID: collection.ID,
+		ResourceID:   resourceID,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.CollectionItem{}).
+			Where("collection_id = ? AND resource_id = ?", collection.ID, resourceID).
+			Count(&existing).Error; err != nil {
+			return fmt.Errorf("failed to check collection: %w", err)
+		}
+		if existing > 0 {
+			return ErrAlreadyInCollection
+		}
+
+		var stats struct {
+			Count    int64
+			Position int
+		}
+		if err := tx.Model(&models.CollectionItem{}).
+			Select("COUNT(*) AS count, COALESCE(MAX(position), -1) + 1 AS position").
+			Where("collection_id = ?", collection.ID).
+			Scan(&stats).Error; err != nil {
+			return fmt.Errorf("failed to check collection: %w", err)
+		}
+		if stats.Count >= maxCollectionItems {
+			return ErrCollectionFull
+		}
+		item.Position = stats.Position
+
+		// A concurrent add of the same resource may have won the race
+		if err := tx.Create(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrAlreadyInCollection
+			}
+			return fmt.Errorf("failed to add to collection: %w", err)
+		}
+
+		if err := tx.Model(collection).Update("item_count", gorm.Expr("item_count + 1")).Error; err != nil {
+			return fmt.Errorf("failed to update collection: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	item.Resource = resource
+	return &item, nil
+}
+
+// RemoveItem removes a resource from a collection
+func (s *CollectionService) RemoveItem(collectionID, userID, resourceID uuid.UUID) error {
+	collection, err := s.getOwned(collectionID, userID, false)
+	if err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("collection_id = ? AND resource_id = ?", collection.ID, resourceID).
+			Delete(&models.CollectionItem{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to remove from collection: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotInCollection
+		}
+
+		if err := tx.Model(collection).Update("item_count", gorm.Expr("item_count - 1")).Error; err != nil {
+			return fmt.Errorf("failed to update collection: %w", err)
+		}
+		return nil
+	})
+}
+
+// ReorderItems puts the resources of a collection in the given order, which
+// must list every resource in the collection exactly once
+func (s *CollectionService) ReorderItems(collectionID, userID uuid.UUID, resourceIDs []uuid.UUID) (*models.Collection, error) {
+	collection, err := s.getOwned(collectionID, userID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var current []uuid.UUID
+		if err := tx.Model(&models.CollectionItem{}).
+			Where("collection_id = ?", collection.ID).
+			Pluck("resource_id", &current).Error; err != nil {
+			return fmt.Errorf("failed to list collection items: %w", err)
+		}
+
+		if len(resourceIDs) != len(current) {
+			return ErrInvalidOrder
+		}
+		inCollection := make(map[uuid.UUID]bool, len(current))
+		for _, id := range current {
+			inCollection[id] = true
+		}
+		for _, id := range resourceIDs {
+			if !inCollection[id] {
+				return ErrInvalidOrder
+			}
+			// Each resource may only be listed once
+			delete(inCollection, id)
+		}
+
+		for position, id := range resourceIDs {
+			if err := tx.Model(&models.CollectionItem{}).
+				Where("collection_id = ? AND resource_id = ?", collection.ID, id).
+				Update("position", position).Error; err != nil {
+				return fmt.Errorf("failed to reorder collection: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCollection(collection.ID, &userID)
+}
+
+// ListCollections searches public collections by title and description
+func (s *CollectionService) ListCollections(req ListCollectionsRequest) ([]models.Collection, int64, error) {
+	if req.PageSize <= 0 || req.PageSize > 100 {
+		req.PageSize = 20
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+
+	query := database.DB.Model(&models.Collection{}).
+		Where("visibility = ?", models.CollectionVisibilityPublic)
+
+	if req.Search != "" {
+		query = query.Where("title ILIKE ? OR description ILIKE ?", "%"+req.Search+"%", "%"+req.Search+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count collections: %w", err)
+	}
+
+	switch req.SortBy {
+	case "popular":
+		query = query.Order("follower_count DESC, updated_at DESC")
+	default:
+		query = query.Order("updated_at DESC")
+	}
+
+	collections := []models.Collection{}
+	if err := query.
+		Preload("User").
+		Offset((req.Page - 1) * req.PageSize).
+		Limit(req.PageSize).
+		Find(&collections).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	return collections, total, nil
+}
+
+// ListUserCollections lists the collections shown on a user's profile: all of
+// them to the user themselves, only public ones to everyone else
+func (s *CollectionService) ListUserCollections(ownerID uuid.UUID, viewerID *uuid.UUID, page, pageSize int) ([]models.Collection, int64, error) {
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	query := database.DB.Model(&models.Collection{}).Where("user_id = ?", ownerID)
+	if viewerID == nil || *viewerID != ownerID {
+		query = query.Where("visibility = ?", models.CollectionVisibilityPublic)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count collections: %w", err)
+	}
+
+	collections := []models.Collection{}
+	if err := query.
+		Order("updated_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&collections).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list collections: %w", err)
+	}
+
+	return collections, total, nil
+}
+
+// FollowCollection follows someone else's public or link-only collection
+func (s *CollectionService) FollowCollection(collectionID, userID uuid.UUID) (*models.CollectionFollow, error) {
+	collection, err := s.getVisible(collectionID, &userID)
+	if err != nil {
+		return nil, err
+	}
+	if collection.UserID == userID {
+		return nil, ErrCannotFollowOwnCollection
+	}
+
+	var existing models.CollectionFollow
+	if err := database.DB.Where("collection_id = ? AND user_id = ?", collectionID, userID).First(&existing).Error; err == nil {
+		return nil, ErrAlreadyFollowingCollection
+	}
+
+	follow := models.CollectionFollow{
+		CollectionID: collectionID,
+		UserID:       userID,
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&follow).Error; err != nil {
+			return fmt.Errorf("failed to follow collection: %w", err)
+		}
+		if err := tx.Model(collection).UpdateColumn("follower_count", gorm.Expr("follower_count + 1")).Error; err != nil {
+			return fmt.Errorf("failed to update collection: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var follower models.User
+	if err := database.DB.Where("id = ?", userID).First(&follower).Error; err == nil {
+		if _, err := s.notificationService.Notify(NotificationEvent{
+			UserID:     collection.UserID,
+			ActorID:    &userID,
+			Type:       models.NotificationTypeCollectionFollow,
+			Message:    fmt.Sprintf("%s started following your collection \"%s\"", follower.FullName(), collection.Title),
+			EntityType: "collection",
+			EntityID:   &collection.ID,
+		}); err != nil {
+			fmt.Printf("Warning: failed to send collection follow notification: %v\n", err)
+		}
+	}
+
+	follow.Collection = *collection
+	return &follow, nil
+}
+
+// UnfollowCollection stops following a collection
+func (s *CollectionService) UnfollowCollection(collectionID, userID uuid.UUID) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("collection_id = ? AND user_id = ?", collectionID, userID).
+			Delete(&models.CollectionFollow{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to unfollow collection: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrNotFollowingCollection
+		}
+
+		if err := tx.Model(&models.Collection{}).
+			Where("id = ?", collectionID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count - 1")).Error; err != nil {
+			return fmt.Errorf("failed to update collection: %w", err)
+		}
+		return nil
+	})
+}
+
+// ListFollowedCollections lists the collections a user follows that they can
+// still see, most recently followed first
+func (s *CollectionService) ListFollowedCollections(userID uuid.UUID, page, pageSize int) ([]models.Collection, int64, error) {
+	if pageSize <= 0 || pageSize > 100 {
+		pageSize = 20
+	}
+	if page <= 0 {
+		page = 1
+	}
+
+	query := database.DB.Model(&models.Collection{}).
+		Joins("JOIN collection_follows ON collection_follows.collection_id = collections.id").
+		Where("collection_follows.user_id = ? AND collections.visibility <> ?", userID, models.CollectionVisibilityPrivate)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count followed collections: %w", err)
+	}
+
+	collections := []models.Collection{}
+	if err := query.
+		Preload("User").
+		Order("collection_follows.created_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&collections).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list followed collections: %w", err)
+	}
+
+	return collections, total, nil
+}
+
+// CopyCollection copies a collection the user can see into a new private
+// collection of theirs, with the resources in it they can see
+func (s *CollectionService) CopyCollection(collectionID, userID uuid.UUID) (*models.Collection, error) {
+	source, err := s.getVisible(collectionID, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := visibleItems(source.ID, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := models.Collection{
+		UserID:       userID,
+		Title:        source.Title,
+		Description:  source.Description,
+		Visibility:   models.CollectionVisibilityPrivate,
+		CopiedFromID: &source.ID,
+		ItemCount:    len(items),
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&collection).Error; err != nil {
+			return fmt.Errorf("failed to copy collection: %w", err)
+		}
+
+		if len(items) == 0 {
+			return nil
+		}
+		copies := make([]models.CollectionItem, len(items))
+		for i, item := range items {
+			copies[i] = models.CollectionItem{
+				CollectionID: collection.ID,
+				ResourceID:   item.ResourceID,
+				Position:     i,
+			}
+		}
+		if err := tx.Omit("Resource").Create(&copies).Error; err != nil {
+			return fmt.Errorf("failed to copy collection items: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCollection(collection.ID, &userID)
+}
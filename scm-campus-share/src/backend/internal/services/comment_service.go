@@ -9,6 +9,7 @@ import (
 
 	"github.com/campus-share/backend/internal/database"
 	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/realtime"
 )
 
 var (
@@ -16,11 +17,15 @@ var (
 )
 
 // CommentService handles comment-related operations
-type CommentService struct{}
+type CommentService struct {
+	notificationService *NotificationService
+}
 
 // NewCommentService creates a new comment service
 func NewCommentService() *CommentService {
-	return &CommentService{}
+	return &CommentService{
+		notificationService: NewNotificationService(),
+	}
 }
 
 // CreateCommentRequest represents a request to create a comment
@@ -48,7 +53,57 @@ func (s *CommentService) CreateComment(resourceID, userID uuid.UUID, req CreateC
 		return nil, fmt.Errorf("failed to create comment: %w", err)
 	}
 
-	return s.GetCommentByID(comment.ID)
+	created, err := s.GetCommentByID(comment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	realtime.Publish(realtime.ResourceTopic(resourceID), "comment.created", created)
+	s.notifyComment(&resource, created)
+
+	return created, nil
+}
+
+// notifyComment tells the resource owner, and the parent comment's author for replies,
+// about a new comment. Failures are logged so they never block commenting.
+func (s *CommentService) notifyComment(resource *models.Resource, comment *models.Comment) {
+	event := NotificationEvent{
+		UserID:     resource.UserID,
+		ActorID:    &comment.UserID,
+		Type:       models.NotificationTypeComment,
+		Message:    fmt.Sprintf("%s commented on \"%s\"", comment.User.FullName(), resource.Title),
+		EntityType: "resource",
+		EntityID:   &resource.ID,
+	}
+	if _, err := s.notificationService.Notify(event); err != nil {
+		fmt.Printf("Warning: failed to send comment notification: %v\n", err)
+	}
+
+	if comment.ParentID == nil {
+		return
+	}
+
+	var parent models.Comment
+	if err := database.DB.Where("id = ?", *comment.ParentID).First(&parent).Error; err != nil {
+		return
+	}
+
+	// The resource owner was already notified above
+	if parent.UserID == resource.UserID {
+		return
+	}
+
+	event = NotificationEvent{
+		UserID:     parent.UserID,
+		ActorID:    &comment.UserID,
+		Type:       models.NotificationTypeCommentReply,
+		Message:    fmt.Sprintf("%s replied to your comment on \"%s\"", comment.User.FullName(), resource.Title),
+		EntityType: "comment",
+		EntityID:   &comment.ID,
+	}
+	if _, err := s.notificationService.Notify(event); err != nil {
+		fmt.Printf("Warning: failed to send comment reply notification: %v\n", err)
+	}
 }
 
 // GetCommentByID retrieves a comment by ID
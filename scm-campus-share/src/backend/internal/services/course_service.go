@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var (
+	ErrAlreadyCourseTA = errors.New("user is already a TA for this course")
+	ErrNotCourseTA     = errors.New("user is not a TA for this course")
+	ErrAlreadyWatching = errors.New("already watching this course")
+	ErrNotWatching     = errors.New("not watching this course")
+)
+
+// CourseService handles course staff such as teaching assistants and course watchers
+type CourseService struct{}
+
+// NewCourseService creates a new course service
+func NewCourseService() *CourseService {
+	return &CourseService{}
+}
+
+// AddTA makes a user a teaching assistant for a course
+func (s *CourseService) AddTA(courseID, userID uuid.UUID) (*models.CourseTA, error) {
+	var course models.Course
+	if err := database.DB.Where("id = ?", courseID).First(&course).Error; err != nil {
+		return nil, ErrCourseNotFound
+	}
+
+	var user models.User
+	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	isTA, err := s.IsCourseTA(courseID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if isTA {
+		return nil, ErrAlreadyCourseTA
+	}
+
+	ta := models.CourseTA{
+		CourseID: courseID,
+		UserID:   userID,
+		User:     user,
+	}
+
+	if err := database.DB.Omit("User").Create(&ta).Error; err != nil {
+		return nil, fmt.Errorf("failed to add TA: %w", err)
+	}
+
+	return &ta, nil
+}
+
+// RemoveTA removes a user's teaching assistant role for a course
+func (s *CourseService) RemoveTA(courseID, userID uuid.UUID) error {
+	result := database.DB.Where("course_id = ? AND user_id = ?", courseID, userID).Delete(&models.CourseTA{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove TA: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotCourseTA
+	}
+
+	return nil
+}
+
+// ListTAs lists the teaching assistants of a course
+func (s *CourseService) ListTAs(courseID uuid.UUID) ([]models.CourseTA, error) {
+	var tas []models.CourseTA
+	if err := database.DB.
+		Preload("User").
+		Where("course_id = ?", courseID).
+		Order("created_at ASC").
+		Find(&tas).Error; err != nil {
+		return nil, fmt.Errorf("failed to list TAs: %w", err)
+	}
+
+	return tas, nil
+}
+
+// IsCourseTA checks if a user is a teaching assistant for a course
+func (s *CourseService) IsCourseTA(courseID, userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := database.DB.Model(&models.CourseTA{}).
+		Where("course_id = ? AND user_id = ?", courseID, userID).
+		Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check TA: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+// WatchCourse subscribes a user to new resources in a course
+func (s *CourseService) WatchCourse(userID, courseID uuid.UUID) (*models.CourseWatch, error) {
+	var course models.Course
+	if err := database.DB.Where("id = ?", courseID).First(&course).Error; err != nil {
+		return nil, ErrCourseNotFound
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.CourseWatch{}).
+		Where("user_id = ? AND course_id = ?", userID, courseID).
+		Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check watch: %w", err)
+	}
+	if count > 0 {
+		return nil, ErrAlreadyWatching
+	}
+
+	watch := models.CourseWatch{
+		UserID:   userID,
+		CourseID: courseID,
+		Course:   course,
+	}
+
+	if err := database.DB.Omit("Course").Create(&watch).Error; err != nil {
+		return nil, fmt.Errorf("failed to watch course: %w", err)
+	}
+
+	return &watch, nil
+}
+
+// UnwatchCourse removes a user's subscription to a course
+func (s *CourseService) UnwatchCourse(userID, courseID uuid.UUID) error {
+	result := database.DB.Where("user_id = ? AND course_id = ?", userID, courseID).Delete(&models.CourseWatch{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to unwatch course: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotWatching
+	}
+
+	return nil
+}
+
+// ListWatchedCourses lists the courses a user watches
+func (s *CourseService) ListWatchedCourses(userID uuid.UUID) ([]models.CourseWatch, error) {
+	var watches []models.CourseWatch
+	if err := database.DB.
+		Preload("Course").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&watches).Error; err != nil {
+		return nil, fmt.Errorf("failed to list watched courses: %w", err)
+	}
+
+	return watches, nil
+}
@@ -0,0 +1,303 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/config"
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/mailer"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var (
+	ErrInvalidDigestFrequency  = errors.New("invalid digest frequency")
+	ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+)
+
+// digestSectionLimit caps how many items each digest section lists
+const digestSectionLimit = 10
+
+// DigestService builds and sends the email digest
+type DigestService struct {
+	mailer              mailer.Mailer
+	cfg                 *config.MailConfig
+	notificationService *NotificationService
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(cfg *config.MailConfig, m mailer.Mailer) *DigestService {
+	return &DigestService{
+		mailer:              m,
+		cfg:                 cfg,
+		notificationService: NewNotificationService(),
+	}
+}
+
+// GetPreference returns a user's digest preference, which is "none" until they subscribe
+func (s *DigestService) GetPreference(userID uuid.UUID) (*models.DigestPreference, error) {
+	var preference models.DigestPreference
+	if err := database.DB.Where("user_id = ?", userID).First(&preference).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.DigestPreference{
+				UserID:    userID,
+				Frequency: models.DigestFrequencyNone,
+			}, nil
+		}
+		return nil, fmt.Errorf("failed to get digest preference: %w", err)
+	}
+
+	return &preference, nil
+}
+
+// UpdateDigestPreferenceRequest represents a request to change the digest frequency
+type UpdateDigestPreferenceRequest struct {
+	Frequency models.DigestFrequency `json:"frequency" binding:"required"`
+}
+
+// UpdatePreference sets how often a user receives the digest
+func (s *DigestService) UpdatePreference(userID uuid.UUID, req UpdateDigestPreferenceRequest) (*models.DigestPreference, error) {
+	switch req.Frequency {
+	case models.DigestFrequencyNone, models.DigestFrequencyDaily, models.DigestFrequencyWeekly:
+	default:
+		return nil, ErrInvalidDigestFrequency
+	}
+
+	preference, err := s.GetPreference(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preference.Frequency = req.Frequency
+
+	if preference.ID == uuid.Nil {
+		token, err := generateUnsubscribeToken()
+		if err != nil {
+			return nil, err
+		}
+		preference.UnsubscribeToken = token
+
+		if err := database.DB.Create(preference).Error; err != nil {
+			return nil, fmt.Errorf("failed to save digest preference: %w", err)
+		}
+		return preference, nil
+	}
+
+	if err := database.DB.Model(preference).Update("frequency", preference.Frequency).Error; err != nil {
+		return nil, fmt.Errorf("failed to save digest preference: %w", err)
+	}
+
+	return preference, nil
+}
+
+// Unsubscribe turns the digest off for the user an unsubscribe token belongs to
+func (s *DigestService) Unsubscribe(token string) error {
+	if token == "" {
+		return ErrInvalidUnsubscribeToken
+	}
+
+	result := database.DB.Model(&models.DigestPreference{}).
+		Where("unsubscribe_token = ?", token).
+		Update("frequency", models.DigestFrequencyNone)
+	if result.Error != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidUnsubscribeToken
+	}
+
+	return nil
+}
+
+// DigestItem is a resource listed in a digest
+type DigestItem struct {
+	Title  string
+	Author string
+	Course string
+	URL    string
+}
+
+// Digest is the data rendered into a digest email
+type Digest struct {
+	FirstName         string
+	Period            string
+	FollowedResources []DigestItem
+	CourseResources   []DigestItem
+	UnreadCount       int64
+	Notifications     []models.Notification
+	UnsubscribeURL    string
+}
+
+// IsEmpty reports whether the digest has nothing worth sending
+func (d *Digest) IsEmpty() bool {
+	return len(d.FollowedResources) == 0 && len(d.CourseResources) == 0 && d.UnreadCount == 0
+}
+
+// BuildDigest gathers what happened for a user since the given time: new resources
+// from followed users and watched courses, and unread notifications
+func (s *DigestService) BuildDigest(user *models.User, preference *models.DigestPreference, since time.Time) (*Digest, error) {
+	digest := &Digest{
+		FirstName:      user.FirstName,
+		Period:         string(preference.Frequency),
+		UnsubscribeURL: fmt.Sprintf("%s/api/v1/digest/unsubscribe?token=%s", s.cfg.APIURL, preference.UnsubscribeToken),
+	}
+
+	var followed []models.Resource
+	if err := database.DB.
+		Preload("User").
+		Preload("Course").
+		Where("user_id IN (?)", database.DB.Model(&models.Follow{}).Select("following_id").Where("follower_id = ?", user.ID)).
+		Where("is_approved = ? AND created_at > ?", true, since).
+		Scopes(visibleResources(&user.ID)).
+		Order("created_at DESC").
+		Limit(digestSectionLimit).
+		Find(&followed).Error; err != nil {
+		return nil, fmt.Errorf("failed to get followed resources: %w", err)
+	}
+
+	seen := make([]uuid.UUID, 0, len(followed))
+	for _, resource := range followed {
+		digest.FollowedResources = append(digest.FollowedResources, s.digestItem(&resource))
+		seen = append(seen, resource.ID)
+	}
+
+	query := database.DB.
+		Preload("User").
+		Preload("Course").
+		Where("course_id IN (?)", database.DB.Model(&models.CourseWatch{}).Select("course_id").Where("user_id = ?", user.ID)).
+		Where("is_approved = ? AND created_at > ? AND user_id <> ?", true, since, user.ID).
+		Scopes(visibleResources(&user.ID))
+	// Resources from followed users are already listed above
+	if len(seen) > 0 {
+		query = query.Where("id NOT IN ?", seen)
+	}
+
+	var courseResources []models.Resource
+	if err := query.Order("created_at DESC").Limit(digestSectionLimit).Find(&courseResources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get course resources: %w", err)
+	}
+	for _, resource := range courseResources {
+		digest.CourseResources = append(digest.CourseResources, s.digestItem(&resource))
+	}
+
+	notifications, unread, err := s.notificationService.ListNotifications(user.ID, ListNotificationsRequest{
+		PageSize:   digestSectionLimit,
+		UnreadOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	digest.Notifications = notifications
+	digest.UnreadCount = unread
+
+	return digest, nil
+}
+
+func (s *DigestService) digestItem(resource *models.Resource) DigestItem {
+	item := DigestItem{
+		Title:  resource.Title,
+		Author: resource.User.FullName(),
+		URL:    fmt.Sprintf("%s/resources/%s", s.cfg.AppURL, resource.ID),
+	}
+	if resource.Course != nil {
+		item.Course = resource.Course.Code
+	}
+	return item
+}
+
+// SendDueDigests sends the digest to every subscriber whose period has elapsed
+// and returns how many emails were sent. Failures for one user are logged and
+// do not stop the others.
+func (s *DigestService) SendDueDigests(now time.Time) (int, error) {
+	var preferences []models.DigestPreference
+	if err := database.DB.
+		Preload("User").
+		Where("(frequency = ? AND (last_sent_at IS NULL OR last_sent_at <= ?)) OR (frequency = ? AND (last_sent_at IS NULL OR last_sent_at <= ?))",
+			models.DigestFrequencyDaily, now.Add(-digestPeriod(models.DigestFrequencyDaily)),
+			models.DigestFrequencyWeekly, now.Add(-digestPeriod(models.DigestFrequencyWeekly))).
+		Find(&preferences).Error; err != nil {
+		return 0, fmt.Errorf("failed to get digest subscribers: %w", err)
+	}
+
+	sent := 0
+	for i := range preferences {
+		preference := &preferences[i]
+		if !preference.User.IsActive || preference.User.IsBanned {
+			continue
+		}
+
+		delivered, err := s.sendDigest(preference, now)
+		if err != nil {
+			fmt.Printf("Warning: failed to send digest to user %s: %v\n", preference.UserID, err)
+			continue
+		}
+		if delivered {
+			sent++
+		}
+	}
+
+	return sent, nil
+}
+
+// sendDigest builds, renders and sends one user's digest. Empty digests are skipped
+// but still count as sent so the next one covers a fresh period.
+func (s *DigestService) sendDigest(preference *models.DigestPreference, now time.Time) (bool, error) {
+	since := now.Add(-digestPeriod(preference.Frequency))
+	if preference.LastSentAt != nil {
+		since = *preference.LastSentAt
+	}
+
+	digest, err := s.BuildDigest(&preference.User, preference, since)
+	if err != nil {
+		return false, err
+	}
+
+	delivered := false
+	if !digest.IsEmpty() {
+		text, html, err := mailer.Render("digest", digest)
+		if err != nil {
+			return false, err
+		}
+
+		if err := s.mailer.Send(mailer.Message{
+			To:       preference.User.Email,
+			Subject:  fmt.Sprintf("Your %s Campus Share digest", preference.Frequency),
+			TextBody: text,
+			HTMLBody: html,
+			Headers: map[string]string{
+				"List-Unsubscribe":      "<" + digest.UnsubscribeURL + ">",
+				"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+			},
+		}); err != nil {
+			return false, err
+		}
+		delivered = true
+	}
+
+	if err := database.DB.Model(preference).Update("last_sent_at", now).Error; err != nil {
+		return delivered, fmt.Errorf("failed to record digest: %w", err)
+	}
+
+	return delivered, nil
+}
+
+func digestPeriod(frequency models.DigestFrequency) time.Duration {
+	if frequency == models.DigestFrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
+
+func generateUnsubscribeToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate unsubscribe token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
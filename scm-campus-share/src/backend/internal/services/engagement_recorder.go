@@ -0,0 +1,206 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+// EngagementRecorder buffers engagement events in memory and writes them in
+// batches, so that counting a view does not cost a database write per request.
+// Each batch is inserted and added to the view and download counters in one
+// statement; events whose dedup key was already recorded are skipped.
+type EngagementRecorder struct {
+	mu      sync.Mutex
+	pending []models.EngagementEvent
+	keys    map[string]bool
+
+	batchSize int
+	flushNow  chan struct{}
+	stop      chan struct{}
+	stopped   chan struct{}
+}
+
+// NewEngagementRecorder creates a recorder that flushes every flushInterval,
+// or sooner once batchSize events are waiting
+func NewEngagementRecorder(flushInterval time.Duration, batchSize int) *EngagementRecorder {
+	if flushInterval <= 0 {
+		flushInterval = 10 * time.Second
+	}
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	r := &EngagementRecorder{
+		keys:      make(map[string]bool),
+		batchSize: batchSize,
+		flushNow:  make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	go r.run(flushInterval)
+
+	return r
+}
+
+// Add queues an event for the next flush
+func (r *EngagementRecorder) Add(event models.EngagementEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// The same viewer twice in one batch only needs to be written once
+	if event.DedupKey != nil {
+		if r.keys[*event.DedupKey] {
+			return
+		}
+		r.keys[*event.DedupKey] = true
+	}
+
+	r.pending = append(r.pending, event)
+	if len(r.pending) >= r.batchSize {
+		select {
+		case r.flushNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes all queued events
+func (r *EngagementRecorder) Flush() error {
+	r.mu.Lock()
+	events := r.pending
+	r.pending = nil
+	r.keys = make(map[string]bool)
+	r.mu.Unlock()
+
+	return writeEngagementEvents(events)
+}
+
+// Close stops the background flushing and writes what is still queued
+func (r *EngagementRecorder) Close() error {
+	close(r.stop)
+	<-r.stopped
+	return r.Flush()
+}
+
+func (r *EngagementRecorder) run(flushInterval time.Duration) {
+	defer close(r.stopped)
+
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		case <-r.flushNow:
+		}
+
+		// A failed batch is dropped rather than retried, so an unavailable
+		// database cannot make the buffer grow without bound
+		if err := r.Flush(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+// defaultRecorder batches events recorded by services; it is nil in
+// command-line tools, which write events as they happen
+var defaultRecorder *EngagementRecorder
+
+// SetEngagementRecorder sets the recorder used for engagement events
+func SetEngagementRecorder(r *EngagementRecorder) {
+	defaultRecorder = r
+}
+
+// engagementInsertLimit caps the events written per statement, keeping it
+// well under the database's limit on bind parameters
+const engagementInsertLimit = 1000
+
+// writeEngagementEvents inserts events, skipping those whose dedup key exists,
+// and adds the inserted views and downloads to the resource and topic counters
+func writeEngagementEvents(events []models.EngagementEvent) error {
+	for len(events) > 0 {
+		n := len(events)
+		if n > engagementInsertLimit {
+			n = engagementInsertLimit
+		}
+		if err := insertEngagementEvents(events[:n]); err != nil {
+			return err
+		}
+		events = events[n:]
+	}
+
+	return nil
+}
+
+func insertEngagementEvents(events []models.EngagementEvent) error {
+	placeholders := make([]string, len(events))
+	args := make([]interface{}, 0, len(events)*9)
+	for i, event := range events {
+		if event.ID == uuid.Nil {
+			event.ID = uuid.New()
+		}
+		if event.CreatedAt.IsZero() {
+			event.CreatedAt = time.Now()
+		}
+		if event.Value == 0 {
+			event.Value = 1
+		}
+		var source interface{}
+		if event.Source != "" {
+			source = event.Source
+		}
+		placeholders[i] = "(?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		args = append(args, event.ID, event.EntityType, event.EntityID, event.UserID, event.Type, event.Value, source, event.DedupKey, event.CreatedAt)
+	}
+
+	var inserted int64
+	if err := database.DB.Raw(`
+		WITH inserted AS (
+			INSERT INTO engagement_events (id, entity_type, entity_id, user_id, type, value, source, dedup_key, created_at)
+			VALUES `+strings.Join(placeholders, ", ")+`
+			ON CONFLICT (dedup_key) DO NOTHING
+			RETURNING entity_type, entity_id, type
+		),
+		resource_counts AS (
+			UPDATE resources SET
+				view_count = view_count + c.views,
+				download_count = download_count + c.downloads
+			FROM (
+				SELECT entity_id,
+				       COUNT(*) FILTER (WHERE type = 'view') AS views,
+				       COUNT(*) FILTER (WHERE type = 'download') AS downloads
+				FROM inserted
+				WHERE entity_type = 'resource' AND type IN ('view', 'download')
+				GROUP BY entity_id
+			) c
+			WHERE resources.id = c.entity_id
+			RETURNING resources.id
+		),
+		topic_counts AS (
+			UPDATE forum_topics SET view_count = view_count + c.views
+			FROM (
+				SELECT entity_id, COUNT(*) AS views
+				FROM inserted
+				WHERE entity_type = 'topic' AND type = 'view'
+				GROUP BY entity_id
+			) c
+			WHERE forum_topics.id = c.entity_id
+			RETURNING forum_topics.id
+		)
+		SELECT COUNT(*) FROM inserted`, args...).
+		Scan(&inserted).Error; err != nil {
+		return fmt.Errorf("failed to record %d engagement events: %w", len(events), err)
+	}
+
+	return nil
+}
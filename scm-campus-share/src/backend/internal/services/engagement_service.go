@@ -0,0 +1,253 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+// Trending tuning. An event counts half as much every trendingHalfLife;
+// events older than trendingWindow are ignored.
+const (
+	trendingHalfLife = 48 * time.Hour
+	trendingWindow   = 14 * 24 * time.Hour
+)
+
+// A viewer counts once per window: repeated views within half an hour, or
+// repeated downloads within a day, are the same visit
+const (
+	viewDedupWindow     = 30 * time.Minute
+	downloadDedupWindow = 24 * time.Hour
+)
+
+// engagementWeights is how much each kind of event adds to a trending score
+var engagementWeights = []struct {
+	Type   models.EngagementType
+	Weight float64
+}{
+	{models.EngagementTypeView, 1},
+	{models.EngagementTypeDownload, 3},
+	{models.EngagementTypeBookmark, 4},
+	{models.EngagementTypeVote, 2},
+	{models.EngagementTypeReply, 3},
+}
+
+// EngagementService records engagement events and ranks what is trending
+type EngagementService struct{}
+
+// NewEngagementService creates a new engagement service
+func NewEngagementService() *EngagementService {
+	return &EngagementService{}
+}
+
+// Record stores an engagement event. userID is nil for anonymous users.
+func (s *EngagementService) Record(entityType string, entityID uuid.UUID, userID *uuid.UUID, engagementType models.EngagementType, value int) error {
+	return s.record(models.EngagementEvent{
+		EntityType: entityType,
+		EntityID:   entityID,
+		UserID:     userID,
+		Type:       engagementType,
+		Value:      value,
+	})
+}
+
+// Viewer describes who viewed or downloaded something
+type Viewer struct {
+	UserID    *uuid.UUID // Nil for anonymous viewers
+	IP        string
+	UserAgent string
+	Prefetch  bool                    // The client fetched ahead of the user actually opening it
+	Source    models.EngagementSource // Where the viewer came from; anything unknown counts as direct
+}
+
+// RecordView counts a view or download of an entity owned by ownerID. Each viewer,
+// identified by their account or else their IP address, counts once per window.
+// Owners, known crawlers and prefetches are not counted.
+func (s *EngagementService) RecordView(entityType string, entityID, ownerID uuid.UUID, engagementType models.EngagementType, viewer Viewer) error {
+	if viewer.Prefetch || isCrawler(viewer.UserAgent) {
+		return nil
+	}
+	if viewer.UserID != nil && *viewer.UserID == ownerID {
+		return nil
+	}
+
+	viewerKey := "ip:" + viewer.IP
+	if viewer.UserID != nil {
+		viewerKey = "user:" + viewer.UserID.String()
+	}
+
+	window := viewDedupWindow
+	if engagementType == models.EngagementTypeDownload {
+		window = downloadDedupWindow
+	}
+	now := time.Now()
+	bucket := now.Truncate(window).Unix()
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%d", entityType, entityID, engagementType, viewerKey, bucket)))
+	dedupKey := hex.EncodeToString(sum[:])
+
+	return s.record(models.EngagementEvent{
+		EntityType: entityType,
+		EntityID:   entityID,
+		UserID:     viewer.UserID,
+		Type:       engagementType,
+		Value:      1,
+		Source:     engagementSource(viewer.Source),
+		DedupKey:   &dedupKey,
+		CreatedAt:  now,
+	})
+}
+
+// engagementSource returns source if it is known and direct otherwise
+func engagementSource(source models.EngagementSource) models.EngagementSource {
+	for _, s := range models.EngagementSources {
+		if s == source {
+			return source
+		}
+	}
+	return models.EngagementSourceDirect
+}
+
+// record queues an event with the engagement recorder, or writes it straight
+// away when there is none
+func (s *EngagementService) record(event models.EngagementEvent) error {
+	if defaultRecorder != nil {
+		defaultRecorder.Add(event)
+		return nil
+	}
+
+	return writeEngagementEvents([]models.EngagementEvent{event})
+}
+
+// crawlerSignatures are user agent fragments of crawlers, link previewers and scripts
+var crawlerSignatures = []string{
+	"bot", "crawl", "spider", "slurp", "archiver", "facebookexternalhit",
+	"embedly", "preview", "headless", "lighthouse", "curl", "wget",
+	"python-requests", "go-http-client", "java/", "okhttp",
+}
+
+// isCrawler reports whether a user agent belongs to a known crawler or script
+func isCrawler(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, signature := range crawlerSignatures {
+		if strings.Contains(userAgent, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// TrendingScores returns a subquery of entity_id and score for entities of a type
+// with recent engagement. Each event adds its weight, halved for every half-life
+// since it happened, so recent activity outranks a large but old total.
+func TrendingScores(entityType string) *gorm.DB {
+	// The weights are constants, so they are written into the SQL rather than bound
+	weight := "CASE type"
+	for _, w := range engagementWeights {
+		weight += fmt.Sprintf(" WHEN '%s' THEN %g", w.Type, w.Weight)
+	}
+	weight += " ELSE 0 END"
+
+	return database.DB.Model(&models.EngagementEvent{}).
+		Select(fmt.Sprintf("entity_id, SUM((%s) * value * POWER(0.5, EXTRACT(EPOCH FROM (NOW() - created_at)) / %g)) AS score",
+			weight, trendingHalfLife.Seconds())).
+		Where("entity_type = ? AND created_at > ?", entityType, time.Now().Add(-trendingWindow)).
+		Group("entity_id")
+}
+
+// OrderByTrending sorts a query on table by trending score, entities without
+// recent engagement last and newest first
+func OrderByTrending(entityType, table string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Joins("LEFT JOIN (?) AS trending ON trending.entity_id = "+table+".id", TrendingScores(entityType)).
+			Order("COALESCE(trending.score, 0) DESC, " + table + ".created_at DESC")
+	}
+}
+
+// TrendingRequest represents a request for trending resources or topics
+type TrendingRequest struct {
+	UniversityID *uuid.UUID `form:"university_id"`
+	DepartmentID *uuid.UUID `form:"department_id"`
+	CourseID     *uuid.UUID `form:"course_id"`
+	Limit        int        `form:"limit"`
+}
+
+// GetTrendingResources returns the resources with the highest trending scores,
+// optionally within a university, department or course. Only resources with
+// recent engagement are included. userID is nil for anonymous users.
+func (s *EngagementService) GetTrendingResources(req TrendingRequest, userID *uuid.UUID) ([]models.Resource, error) {
+	resources := []models.Resource{}
+	if err := database.DB.Model(&models.Resource{}).
+		Preload("User").
+		Preload("University").
+		Preload("Course").
+		Preload("Tags.Tag").
+		Joins("JOIN (?) AS trending ON trending.entity_id = resources.id", TrendingScores("resource")).
+		Where("is_approved = ? AND trending.score > 0", true).
+		Scopes(visibleResources(userID), catalogScope(req)).
+		Order("trending.score DESC").
+		Limit(trendingLimit(req.Limit)).
+		Find(&resources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get trending resources: %w", err)
+	}
+
+	markBookmarked(resources, userID)
+
+	return resources, nil
+}
+
+// GetTrendingTopics returns the forum topics with the highest trending scores,
+// optionally within a university, department or course
+func (s *EngagementService) GetTrendingTopics(req TrendingRequest) ([]models.ForumTopic, error) {
+	topics := []models.ForumTopic{}
+	if err := database.DB.Model(&models.ForumTopic{}).
+		Preload("User").
+		Preload("Course").
+		Preload("University").
+		Preload("Department").
+		Joins("JOIN (?) AS trending ON trending.entity_id = forum_topics.id", TrendingScores("topic")).
+		Where("is_approved = ? AND is_deleted = ? AND trending.score > 0", true, false).
+		Scopes(catalogScope(req)).
+		Order("trending.score DESC").
+		Limit(trendingLimit(req.Limit)).
+		Find(&topics).Error; err != nil {
+		return nil, fmt.Errorf("failed to get trending topics: %w", err)
+	}
+
+	return topics, nil
+}
+
+func trendingLimit(limit int) int {
+	if limit <= 0 {
+		return 10
+	}
+	if limit > 50 {
+		return 50
+	}
+	return limit
+}
+
+// catalogScope limits a resource or topic query to a university, department or course
+func catalogScope(req TrendingRequest) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if req.UniversityID != nil {
+			db = db.Where("university_id = ?", req.UniversityID)
+		}
+		if req.DepartmentID != nil {
+			db = db.Where("department_id = ?", req.DepartmentID)
+		}
+		if req.CourseID != nil {
+			db = db.Where("course_id = ?", req.CourseID)
+		}
+		return db
+	}
+}
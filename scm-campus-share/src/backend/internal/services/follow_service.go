@@ -18,11 +18,17 @@ var (
 )
 
 // FollowService handles user following operations
-type FollowService struct{}
+type FollowService struct {
+	notificationService *NotificationService
+	activityService     *ActivityService
+}
 
 // NewFollowService creates a new follow service
 func NewFollowService() *FollowService {
-	return &FollowService{}
+	return &FollowService{
+		notificationService: NewNotificationService(),
+		activityService:     NewActivityService(),
+	}
 }
 
 // FollowUser creates a follow relationship
@@ -53,7 +59,27 @@ func (s *FollowService) FollowUser(followerID, followingID uuid.UUID) (*models.F
 		return nil, fmt.Errorf("failed to follow user: %w", err)
 	}
 
-	return s.GetFollowByID(follow.ID)
+	created, err := s.GetFollowByID(follow.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.notificationService.Notify(NotificationEvent{
+		UserID:     followingID,
+		ActorID:    &followerID,
+		Type:       models.NotificationTypeFollow,
+		Message:    fmt.Sprintf("%s started following you", created.Follower.FullName()),
+		EntityType: "user",
+		EntityID:   &followerID,
+	}); err != nil {
+		fmt.Printf("Warning: failed to send follow notification: %v\n", err)
+	}
+
+	if err := s.activityService.Record(followerID, models.ActivityTypeUserFollowed, "user", followingID, nil); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	return created, nil
 }
 
 // UnfollowUser removes a follow relationship
@@ -70,6 +96,10 @@ func (s *FollowService) UnfollowUser(followerID, followingID uuid.UUID) error {
 		return fmt.Errorf("failed to unfollow user: %w", err)
 	}
 
+	if err := s.activityService.Remove(followerID, models.ActivityTypeUserFollowed, "user", followingID); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	return nil
 }
 
@@ -163,7 +193,8 @@ func (s *FollowService) IsFollowing(followerID, followingID uuid.UUID) (bool, er
 	return count > 0, nil
 }
 
-// GetActivityFeed gets resources from users that the current user follows
+// GetActivityFeed gets resources from users that the current user follows.
+// ActivityService.GetFeed provides the full activity stream.
 func (s *FollowService) GetActivityFeed(userID uuid.UUID, page, pageSize int) ([]models.Resource, int64, error) {
 	if pageSize <= 0 {
 		pageSize = 20
@@ -172,23 +203,11 @@ func (s *FollowService) GetActivityFeed(userID uuid.UUID, page, pageSize int) ([
 		page = 1
 	}
 
-	// Get list of user IDs that the current user follows
-	var follows []models.Follow
-	if err := database.DB.Where("follower_id = ?", userID).Find(&follows).Error; err != nil {
-		return nil, 0, fmt.Errorf("failed to get following list: %w", err)
-	}
-
-	if len(follows) == 0 {
-		return []models.Resource{}, 0, nil
-	}
-
-	followingIDs := make([]uuid.UUID, len(follows))
-	for i, follow := range follows {
-		followingIDs[i] = follow.FollowingID
-	}
+	// Match followed users with a subquery rather than loading the follow list
+	following := database.DB.Model(&models.Follow{}).Select("following_id").Where("follower_id = ?", userID)
 
 	query := database.DB.Model(&models.Resource{}).
-		Where("user_id IN ? AND is_approved = ?", followingIDs, true).
+		Where("user_id IN (?) AND is_approved = ?", following, true).
 		Preload("User").
 		Preload("University").
 		Preload("Course").
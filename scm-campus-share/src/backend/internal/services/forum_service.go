@@ -3,42 +3,79 @@ package services
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/campus-share/backend/internal/database"
 	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/realtime"
 )
 
 var (
-	ErrTopicNotFound = errors.New("topic not found")
-	ErrReplyNotFound = errors.New("reply not found")
-	ErrTopicLocked   = errors.New("topic is locked")
+	ErrTopicNotFound      = errors.New("topic not found")
+	ErrReplyNotFound      = errors.New("reply not found")
+	ErrTopicLocked        = errors.New("topic is locked")
+	ErrMoveTargetRequired = errors.New("a course, department or university is required")
+	ErrCannotMergeSelf    = errors.New("cannot merge a topic into itself")
+	ErrCourseNotFound     = errors.New("course not found")
+	ErrDepartmentNotFound = errors.New("department not found")
+	ErrUniversityNotFound = errors.New("university not found")
+	ErrPostDeleted        = errors.New("post has been deleted")
+	ErrEmptyEdit          = errors.New("title or content is required")
+	ErrNotAQuestion       = errors.New("topic is not a question")
+	ErrReplyNotInTopic    = errors.New("reply does not belong to this topic")
+	ErrNoAcceptedAnswer   = errors.New("topic has no accepted answer")
+	ErrInvalidTopicType   = errors.New("invalid topic type")
 )
 
+// AcceptedAnswerReputation is the reputation awarded to the author of an accepted answer
+const AcceptedAnswerReputation = 15
+
 // ForumService handles forum-related operations
-type ForumService struct{}
+type ForumService struct {
+	courseService       *CourseService
+	notificationService *NotificationService
+	activityService     *ActivityService
+	engagementService   *EngagementService
+}
 
 // NewForumService creates a new forum service
 func NewForumService() *ForumService {
-	return &ForumService{}
+	return &ForumService{
+		courseService:       NewCourseService(),
+		notificationService: NewNotificationService(),
+		activityService:     NewActivityService(),
+		engagementService:   NewEngagementService(),
+	}
 }
 
 // CreateTopicRequest represents a request to create a forum topic
 type CreateTopicRequest struct {
-	Title        string     `json:"title" binding:"required"`
-	Content      string     `json:"content" binding:"required"`
-	CourseID     *uuid.UUID `json:"course_id,omitempty"`
+	Title        string           `json:"title" binding:"required"`
+	Content      string           `json:"content" binding:"required"`
+	Type         models.TopicType `json:"type,omitempty"` // "discussion" (default) or "question"
+	CourseID     *uuid.UUID       `json:"course_id,omitempty"`
 	UniversityID *uuid.UUID `json:"university_id,omitempty"`
 	DepartmentID *uuid.UUID `json:"department_id,omitempty"`
 }
 
 // CreateTopic creates a new forum topic
 func (s *ForumService) CreateTopic(userID uuid.UUID, req CreateTopicRequest) (*models.ForumTopic, error) {
+	switch req.Type {
+	case "":
+		req.Type = models.TopicTypeDiscussion
+	case models.TopicTypeDiscussion, models.TopicTypeQuestion:
+	default:
+		return nil, ErrInvalidTopicType
+	}
+
 	topic := models.ForumTopic{
 		Title:        req.Title,
 		Content:      req.Content,
+		Type:         req.Type,
 		UserID:       userID,
 		CourseID:     req.CourseID,
 		UniversityID: req.UniversityID,
@@ -50,11 +87,36 @@ func (s *ForumService) CreateTopic(userID uuid.UUID, req CreateTopicRequest) (*m
 		return nil, fmt.Errorf("failed to create topic: %w", err)
 	}
 
+	if err := s.activityService.Record(userID, models.ActivityTypeTopicCreated, "topic", topic.ID, topic.CourseID); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	return s.GetTopicByID(topic.ID)
 }
 
 // GetTopicByID retrieves a topic by ID
 func (s *ForumService) GetTopicByID(topicID uuid.UUID) (*models.ForumTopic, error) {
+	return s.getTopic(topicID)
+}
+
+// ViewTopic retrieves a topic by ID and counts the view. Views of hidden topics are not counted.
+func (s *ForumService) ViewTopic(topicID uuid.UUID, viewer Viewer) (*models.ForumTopic, error) {
+	topic, err := s.getTopic(topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	if topic.IsApproved {
+		if err := s.engagementService.RecordView("topic", topic.ID, topic.UserID, models.EngagementTypeView, viewer); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+
+	return topic, nil
+}
+
+// getTopic loads a topic with its relations without counting a view
+func (s *ForumService) getTopic(topicID uuid.UUID) (*models.ForumTopic, error) {
 	var topic models.ForumTopic
 	if err := database.DB.
 		Preload("User").
@@ -71,9 +133,6 @@ func (s *ForumService) GetTopicByID(topicID uuid.UUID) (*models.ForumTopic, erro
 		return nil, fmt.Errorf("failed to get topic: %w", err)
 	}
 
-	// Increment view count
-	database.DB.Model(&topic).UpdateColumn("view_count", gorm.Expr("view_count + 1"))
-
 	return &topic, nil
 }
 
@@ -85,13 +144,15 @@ type ListTopicsRequest struct {
 	UniversityID *uuid.UUID `form:"university_id"`
 	DepartmentID *uuid.UUID `form:"department_id"`
 	Search       string    `form:"search"`
-	SortBy       string    `form:"sort_by"` // "newest", "popular", "replies"
+	Type         string    `form:"type"`   // "discussion" or "question"
+	Status       string    `form:"status"` // "unanswered" or "solved" (questions only)
+	SortBy       string    `form:"sort_by"` // "newest", "popular", "trending", "replies", "unanswered", "solved"
 }
 
 // ListTopics lists forum topics with filtering
 func (s *ForumService) ListTopics(req ListTopicsRequest) ([]models.ForumTopic, int64, error) {
 	query := database.DB.Model(&models.ForumTopic{}).
-		Where("is_approved = ?", true).
+		Where("is_approved = ? AND is_deleted = ?", true, false).
 		Preload("User").
 		Preload("Course").
 		Preload("University").
@@ -110,6 +171,17 @@ func (s *ForumService) ListTopics(req ListTopicsRequest) ([]models.ForumTopic, i
 	if req.Search != "" {
 		query = query.Where("title ILIKE ? OR content ILIKE ?", "%"+req.Search+"%", "%"+req.Search+"%")
 	}
+	if req.Type != "" {
+		query = query.Where("type = ?", req.Type)
+	}
+
+	// Unanswered means a question without an accepted answer
+	switch req.Status {
+	case "unanswered":
+		query = query.Where("type = ? AND accepted_reply_id IS NULL", models.TopicTypeQuestion)
+	case "solved":
+		query = query.Where("type = ? AND accepted_reply_id IS NOT NULL", models.TopicTypeQuestion)
+	}
 
 	// Get total count
 	var total int64
@@ -121,10 +193,18 @@ func (s *ForumService) ListTopics(req ListTopicsRequest) ([]models.ForumTopic, i
 	switch req.SortBy {
 	case "popular":
 		query = query.Order("upvote_count DESC, reply_count DESC")
+	case "trending":
+		query = query.Scopes(OrderByTrending("topic", "forum_topics"))
 	case "replies":
 		query = query.Order("reply_count DESC, created_at DESC")
 	case "pinned":
 		query = query.Order("is_pinned DESC, created_at DESC")
+	case "unanswered":
+		// Open questions first, those with the fewest replies and oldest first
+		query = query.Order("(type = 'question' AND accepted_reply_id IS NULL) DESC, reply_count ASC, created_at ASC")
+	case "solved":
+		// Most recently solved questions first
+		query = query.Order("solved_at DESC NULLS LAST, created_at DESC")
 	default:
 		query = query.Order("is_pinned DESC, created_at DESC")
 	}
@@ -161,6 +241,10 @@ func (s *ForumService) CreateReply(topicID, userID uuid.UUID, req CreateReplyReq
 		return nil, ErrTopicNotFound
 	}
 
+	if topic.IsDeleted {
+		return nil, ErrTopicNotFound
+	}
+
 	if topic.IsLocked {
 		return nil, ErrTopicLocked
 	}
@@ -179,8 +263,45 @@ func (s *ForumService) CreateReply(topicID, userID uuid.UUID, req CreateReplyReq
 
 	// Increment reply count on topic
 	database.DB.Model(&topic).UpdateColumn("reply_count", gorm.Expr("reply_count + 1"))
+	if err := s.engagementService.Record("topic", topicID, &userID, models.EngagementTypeReply, 1); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	created, err := s.GetReplyByID(reply.ID)
+	if err != nil {
+		return nil, err
+	}
 
-	return s.GetReplyByID(reply.ID)
+	realtime.Publish(realtime.ForumTopic(topicID), "reply.created", created)
+	s.notifyReply(&topic, created)
+
+	return created, nil
+}
+
+// notifyReply tells the topic author, and the parent reply's author for nested replies,
+// about a new reply. Failures are logged so they never block posting.
+func (s *ForumService) notifyReply(topic *models.ForumTopic, reply *models.ForumReply) {
+	recipients := []uuid.UUID{topic.UserID}
+
+	if reply.ParentID != nil {
+		var parent models.ForumReply
+		if err := database.DB.Where("id = ?", *reply.ParentID).First(&parent).Error; err == nil && parent.UserID != topic.UserID {
+			recipients = append(recipients, parent.UserID)
+		}
+	}
+
+	for _, recipientID := range recipients {
+		if _, err := s.notificationService.Notify(NotificationEvent{
+			UserID:     recipientID,
+			ActorID:    &reply.UserID,
+			Type:       models.NotificationTypeForumReply,
+			Message:    fmt.Sprintf("%s replied in \"%s\"", reply.User.FullName(), topic.Title),
+			EntityType: "reply",
+			EntityID:   &reply.ID,
+		}); err != nil {
+			fmt.Printf("Warning: failed to send reply notification: %v\n", err)
+		}
+	}
 }
 
 // GetReplyByID retrieves a reply by ID
@@ -209,7 +330,7 @@ func (s *ForumService) ListReplies(topicID uuid.UUID) ([]models.ForumReply, erro
 		Preload("Replies.User").
 		Where("topic_id = ? AND parent_id IS NULL", topicID).
 		Where("is_approved = ?", true).
-		Order("upvote_count DESC, created_at ASC").
+		Order("is_accepted DESC, upvote_count DESC, created_at ASC").
 		Find(&replies).Error; err != nil {
 		return nil, fmt.Errorf("failed to list replies: %w", err)
 	}
@@ -217,115 +338,579 @@ func (s *ForumService) ListReplies(topicID uuid.UUID) ([]models.ForumReply, erro
 	return replies, nil
 }
 
-// VoteRequest represents a vote request
-type VoteRequest struct {
-	IsUpvote bool `json:"is_upvote"`
+// UpdateTopicRequest represents a request to edit a topic
+type UpdateTopicRequest struct {
+	Title   string `json:"title,omitempty"`
+	Content string `json:"content,omitempty"`
 }
 
-// VoteOnTopic votes on a forum topic
-func (s *ForumService) VoteOnTopic(topicID, userID uuid.UUID, req VoteRequest) error {
-	// Verify topic exists
+// UpdateTopic edits a topic, keeping the previous version as a revision.
+// Moderators may edit any topic, including locked ones.
+func (s *ForumService) UpdateTopic(topicID, userID uuid.UUID, isModerator bool, req UpdateTopicRequest) (*models.ForumTopic, error) {
+	if req.Title == "" && req.Content == "" {
+		return nil, ErrEmptyEdit
+	}
+
+	var topic models.ForumTopic
+	if err := database.DB.Where("id = ?", topicID).First(&topic).Error; err != nil {
+		return nil, ErrTopicNotFound
+	}
+
+	if topic.IsDeleted {
+		return nil, ErrPostDeleted
+	}
+	if topic.UserID != userID && !isModerator {
+		return nil, ErrUnauthorized
+	}
+	if topic.IsLocked && !isModerator {
+		return nil, ErrTopicLocked
+	}
+
+	revision := models.ForumRevision{
+		RevisableType: "topic",
+		RevisableID:   topicID,
+		EditorID:      userID,
+		Action:        "edit",
+		Title:         topic.Title,
+		Content:       topic.Content,
+	}
+
+	now := time.Now()
+	if req.Title != "" {
+		topic.Title = req.Title
+	}
+	if req.Content != "" {
+		topic.Content = req.Content
+	}
+	topic.EditedAt = &now
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&revision).Error; err != nil {
+			return fmt.Errorf("failed to save revision: %w", err)
+		}
+		return tx.Model(&topic).Select("title", "content", "edited_at").Updates(&topic).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update topic: %w", err)
+	}
+
+	return s.getTopic(topicID)
+}
+
+// DeleteTopic soft-deletes a topic, leaving a tombstone so its replies stay readable.
+// The deleted title and content are kept as a revision for moderators.
+func (s *ForumService) DeleteTopic(topicID, userID uuid.UUID, isModerator bool) error {
 	var topic models.ForumTopic
 	if err := database.DB.Where("id = ?", topicID).First(&topic).Error; err != nil {
 		return ErrTopicNotFound
 	}
 
-	// Check if user already voted
-	var existingVote models.ForumVote
-	err := database.DB.Where("user_id = ? AND votable_type = ? AND votable_id = ?", userID, "topic", topicID).First(&existingVote).Error
+	if topic.IsDeleted {
+		return ErrPostDeleted
+	}
+	if topic.UserID != userID && !isModerator {
+		return ErrUnauthorized
+	}
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// Create new vote
-		vote := models.ForumVote{
-			UserID:      userID,
-			IsUpvote:    req.IsUpvote,
-			VotableType: "topic",
-			VotableID:   topicID,
+	revision := models.ForumRevision{
+		RevisableType: "topic",
+		RevisableID:   topicID,
+		EditorID:      userID,
+		Action:        "delete",
+		Title:         topic.Title,
+		Content:       topic.Content,
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&revision).Error; err != nil {
+			return fmt.Errorf("failed to save revision: %w", err)
 		}
-		if err := database.DB.Create(&vote).Error; err != nil {
-			return fmt.Errorf("failed to create vote: %w", err)
+
+		if err := tx.Model(&topic).Updates(map[string]interface{}{
+			"title":      "",
+			"content":    "",
+			"is_deleted": true,
+			"deleted_by": userID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to delete topic: %w", err)
 		}
 
-		// Update topic vote count
-		if req.IsUpvote {
-			database.DB.Model(&topic).UpdateColumn("upvote_count", gorm.Expr("upvote_count + 1"))
-		} else {
-			database.DB.Model(&topic).UpdateColumn("downvote_count", gorm.Expr("downvote_count + 1"))
+		return nil
+	})
+}
+
+// UpdateReplyRequest represents a request to edit a reply
+type UpdateReplyRequest struct {
+	Content string `json:"content" binding:"required"`
+}
+
+// UpdateReply edits a reply, keeping the previous version as a revision.
+// Moderators may edit any reply, including replies in locked topics.
+func (s *ForumService) UpdateReply(replyID, userID uuid.UUID, isModerator bool, req UpdateReplyRequest) (*models.ForumReply, error) {
+	var reply models.ForumReply
+	if err := database.DB.Preload("Topic").Where("id = ?", replyID).First(&reply).Error; err != nil {
+		return nil, ErrReplyNotFound
+	}
+
+	if reply.IsDeleted {
+		return nil, ErrPostDeleted
+	}
+	if reply.UserID != userID && !isModerator {
+		return nil, ErrUnauthorized
+	}
+	if reply.Topic.IsLocked && !isModerator {
+		return nil, ErrTopicLocked
+	}
+
+	revision := models.ForumRevision{
+		RevisableType: "reply",
+		RevisableID:   replyID,
+		EditorID:      userID,
+		Action:        "edit",
+		Content:       reply.Content,
+	}
+
+	now := time.Now()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&revision).Error; err != nil {
+			return fmt.Errorf("failed to save revision: %w", err)
 		}
-	} else if err == nil {
-		// Update existing vote if different
-		if existingVote.IsUpvote != req.IsUpvote {
-			existingVote.IsUpvote = req.IsUpvote
-			database.DB.Save(&existingVote)
+		return tx.Model(&models.ForumReply{}).Where("id = ?", replyID).Updates(map[string]interface{}{
+			"content":   req.Content,
+			"edited_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update reply: %w", err)
+	}
 
-			// Update topic vote counts
-			if req.IsUpvote {
-				database.DB.Model(&topic).UpdateColumn("upvote_count", gorm.Expr("upvote_count + 1"))
-				database.DB.Model(&topic).UpdateColumn("downvote_count", gorm.Expr("downvote_count - 1"))
-			} else {
-				database.DB.Model(&topic).UpdateColumn("downvote_count", gorm.Expr("downvote_count + 1"))
-				database.DB.Model(&topic).UpdateColumn("upvote_count", gorm.Expr("upvote_count - 1"))
+	return s.GetReplyByID(replyID)
+}
+
+// DeleteReply soft-deletes a reply, leaving a tombstone so nested replies keep their place.
+// The deleted content is kept as a revision for moderators. A deleted accepted
+// answer stops being accepted and its author loses the reputation it earned.
+func (s *ForumService) DeleteReply(replyID, userID uuid.UUID, isModerator bool) error {
+	var reply models.ForumReply
+	if err := database.DB.Where("id = ?", replyID).First(&reply).Error; err != nil {
+		return ErrReplyNotFound
+	}
+
+	if reply.IsDeleted {
+		return ErrPostDeleted
+	}
+	if reply.UserID != userID && !isModerator {
+		return ErrUnauthorized
+	}
+
+	revision := models.ForumRevision{
+		RevisableType: "reply",
+		RevisableID:   replyID,
+		EditorID:      userID,
+		Action:        "delete",
+		Content:       reply.Content,
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&revision).Error; err != nil {
+			return fmt.Errorf("failed to save revision: %w", err)
+		}
+
+		if reply.IsAccepted {
+			var topic models.ForumTopic
+			if err := tx.Where("id = ?", reply.TopicID).First(&topic).Error; err != nil {
+				return fmt.Errorf("failed to get topic: %w", err)
+			}
+			if err := s.clearAcceptedAnswer(tx, &topic); err != nil {
+				return err
+			}
+			if err := tx.Model(&topic).UpdateColumns(map[string]interface{}{
+				"accepted_reply_id": nil,
+				"solved_at":         nil,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to clear accepted answer: %w", err)
 			}
 		}
-	} else {
-		return fmt.Errorf("failed to check vote: %w", err)
+
+		if err := tx.Model(&reply).Updates(map[string]interface{}{
+			"content":    "",
+			"is_deleted": true,
+			"deleted_by": userID,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to delete reply: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if reply.IsAccepted {
+		s.removeAcceptedAnswerActivity(replyID)
 	}
 
 	return nil
 }
 
-// VoteOnReply votes on a forum reply
-func (s *ForumService) VoteOnReply(replyID, userID uuid.UUID, req VoteRequest) error {
-	// Verify reply exists
+// ListRevisions lists the edit history of a topic or reply, newest first
+func (s *ForumService) ListRevisions(revisableType string, revisableID uuid.UUID) ([]models.ForumRevision, error) {
+	var revisions []models.ForumRevision
+	if err := database.DB.
+		Preload("Editor").
+		Where("revisable_type = ? AND revisable_id = ?", revisableType, revisableID).
+		Order("created_at DESC").
+		Find(&revisions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// AcceptAnswerRequest represents a request to accept a reply as the answer
+type AcceptAnswerRequest struct {
+	ReplyID uuid.UUID `json:"reply_id" binding:"required"`
+}
+
+// AcceptAnswer marks a reply as the accepted answer to a question topic.
+// The topic author, a TA of the topic's course or a moderator may accept an answer.
+// Reputation moves from the previously accepted answer's author to the new one.
+func (s *ForumService) AcceptAnswer(topicID, replyID, userID uuid.UUID, isModerator bool) (*models.ForumTopic, error) {
+	topic, err := s.getQuestionForAnswering(topicID, userID, isModerator)
+	if err != nil {
+		return nil, err
+	}
+
 	var reply models.ForumReply
 	if err := database.DB.Where("id = ?", replyID).First(&reply).Error; err != nil {
-		return ErrReplyNotFound
+		return nil, ErrReplyNotFound
+	}
+	if reply.TopicID != topicID {
+		return nil, ErrReplyNotInTopic
+	}
+	if reply.IsDeleted {
+		return nil, ErrPostDeleted
+	}
+
+	if topic.AcceptedReplyID != nil && *topic.AcceptedReplyID == replyID {
+		return s.getTopic(topicID)
 	}
 
-	// Check if user already voted
-	var existingVote models.ForumVote
-	err := database.DB.Where("user_id = ? AND votable_type = ? AND votable_id = ?", userID, "reply", replyID).First(&existingVote).Error
+	previousReplyID := topic.AcceptedReplyID
 
-	if errors.Is(err, gorm.ErrRecordNotFound) {
-		// Create new vote
-		vote := models.ForumVote{
-			UserID:      userID,
-			IsUpvote:    req.IsUpvote,
-			VotableType: "reply",
-			VotableID:   replyID,
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.clearAcceptedAnswer(tx, topic); err != nil {
+			return err
 		}
-		if err := database.DB.Create(&vote).Error; err != nil {
-			return fmt.Errorf("failed to create vote: %w", err)
+
+		if err := s.acceptReply(tx, topic, &reply); err != nil {
+			return err
 		}
 
-		// Update reply vote count
-		if req.IsUpvote {
-			database.DB.Model(&reply).UpdateColumn("upvote_count", gorm.Expr("upvote_count + 1"))
-		} else {
-			database.DB.Model(&reply).UpdateColumn("downvote_count", gorm.Expr("downvote_count + 1"))
+		if err := tx.Model(topic).UpdateColumns(map[string]interface{}{
+			"accepted_reply_id": replyID,
+			"solved_at":         time.Now(),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update topic: %w", err)
 		}
-	} else if err == nil {
-		// Update existing vote if different
-		if existingVote.IsUpvote != req.IsUpvote {
-			existingVote.IsUpvote = req.IsUpvote
-			database.DB.Save(&existingVote)
 
-			// Update reply vote counts
-			if req.IsUpvote {
-				database.DB.Model(&reply).UpdateColumn("upvote_count", gorm.Expr("upvote_count + 1"))
-				database.DB.Model(&reply).UpdateColumn("downvote_count", gorm.Expr("downvote_count - 1"))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if previousReplyID != nil {
+		s.removeAcceptedAnswerActivity(*previousReplyID)
+	}
+	if err := s.activityService.Record(reply.UserID, models.ActivityTypeAnswerAccepted, "reply", reply.ID, topic.CourseID); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	return s.getTopic(topicID)
+}
+
+// UnacceptAnswer removes the accepted answer from a question topic
+func (s *ForumService) UnacceptAnswer(topicID, userID uuid.UUID, isModerator bool) (*models.ForumTopic, error) {
+	topic, err := s.getQuestionForAnswering(topicID, userID, isModerator)
+	if err != nil {
+		return nil, err
+	}
+
+	if topic.AcceptedReplyID == nil {
+		return nil, ErrNoAcceptedAnswer
+	}
+
+	previousReplyID := *topic.AcceptedReplyID
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.clearAcceptedAnswer(tx, topic); err != nil {
+			return err
+		}
+
+		return tx.Model(topic).UpdateColumns(map[string]interface{}{
+			"accepted_reply_id": nil,
+			"solved_at":         nil,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.removeAcceptedAnswerActivity(previousReplyID)
+
+	return s.getTopic(topicID)
+}
+
+// removeAcceptedAnswerActivity takes an answer that is no longer accepted out of activity feeds
+func (s *ForumService) removeAcceptedAnswerActivity(replyID uuid.UUID) {
+	var reply models.ForumReply
+	if err := database.DB.Unscoped().Where("id = ?", replyID).First(&reply).Error; err != nil {
+		return
+	}
+
+	if err := s.activityService.Remove(reply.UserID, models.ActivityTypeAnswerAccepted, "reply", replyID); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+}
+
+// getQuestionForAnswering loads a question topic and checks the user may manage its accepted answer
+func (s *ForumService) getQuestionForAnswering(topicID, userID uuid.UUID, isModerator bool) (*models.ForumTopic, error) {
+	var topic models.ForumTopic
+	if err := database.DB.Where("id = ?", topicID).First(&topic).Error; err != nil {
+		return nil, ErrTopicNotFound
+	}
+
+	if topic.IsDeleted {
+		return nil, ErrPostDeleted
+	}
+	if topic.Type != models.TopicTypeQuestion {
+		return nil, ErrNotAQuestion
+	}
+
+	if topic.UserID == userID || isModerator {
+		return &topic, nil
+	}
+
+	if topic.CourseID != nil {
+		isTA, err := s.courseService.IsCourseTA(*topic.CourseID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if isTA {
+			return &topic, nil
+		}
+	}
+
+	return nil, ErrUnauthorized
+}
+
+// acceptReply marks a reply as accepted and awards its author reputation. The
+// caller points the topic at the reply.
+func (s *ForumService) acceptReply(tx *gorm.DB, topic *models.ForumTopic, reply *models.ForumReply) error {
+	if err := tx.Model(reply).UpdateColumn("is_accepted", true).Error; err != nil {
+		return fmt.Errorf("failed to accept reply: %w", err)
+	}
+
+	// Answering your own question earns nothing
+	if reply.UserID != topic.UserID {
+		if err := tx.Model(&models.User{}).Where("id = ?", reply.UserID).
+			UpdateColumn("reputation", gorm.Expr("reputation + ?", AcceptedAnswerReputation)).Error; err != nil {
+			return fmt.Errorf("failed to award reputation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// clearAcceptedAnswer unmarks the topic's current accepted answer and takes back its reputation
+func (s *ForumService) clearAcceptedAnswer(tx *gorm.DB, topic *models.ForumTopic) error {
+	if topic.AcceptedReplyID == nil {
+		return nil
+	}
+
+	var previous models.ForumReply
+	if err := tx.Where("id = ?", topic.AcceptedReplyID).First(&previous).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get accepted reply: %w", err)
+	}
+
+	if err := tx.Model(&previous).UpdateColumn("is_accepted", false).Error; err != nil {
+		return fmt.Errorf("failed to unaccept reply: %w", err)
+	}
+
+	if previous.UserID != topic.UserID {
+		if err := tx.Model(&models.User{}).Where("id = ?", previous.UserID).
+			UpdateColumn("reputation", gorm.Expr("reputation - ?", AcceptedAnswerReputation)).Error; err != nil {
+			return fmt.Errorf("failed to revoke reputation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// VoteRequest represents a vote request
+type VoteRequest struct {
+	IsUpvote bool `json:"is_upvote"`
+}
+
+// VoteOnTopic votes on a forum topic
+func (s *ForumService) VoteOnTopic(topicID, userID uuid.UUID, req VoteRequest) error {
+	return s.castVote("topic", topicID, userID, &req.IsUpvote)
+}
+
+// VoteOnReply votes on a forum reply
+func (s *ForumService) VoteOnReply(replyID, userID uuid.UUID, req VoteRequest) error {
+	return s.castVote("reply", replyID, userID, &req.IsUpvote)
+}
+
+// ClearTopicVote removes a user's vote on a forum topic
+func (s *ForumService) ClearTopicVote(topicID, userID uuid.UUID) error {
+	return s.castVote("topic", topicID, userID, nil)
+}
+
+// ClearReplyVote removes a user's vote on a forum reply
+func (s *ForumService) ClearReplyVote(replyID, userID uuid.UUID) error {
+	return s.castVote("reply", replyID, userID, nil)
+}
+
+// castVote creates, changes or (with a nil isUpvote) removes a user's vote and
+// adjusts the vote counters in the same transaction. The voted row is locked so
+// concurrent votes on it are applied one at a time.
+func (s *ForumService) castVote(votableType string, votableID, userID uuid.UUID, isUpvote *bool) error {
+	var votable interface{}
+	notFound := ErrTopicNotFound
+	switch votableType {
+	case "topic":
+		votable = &models.ForumTopic{}
+	case "reply":
+		votable = &models.ForumReply{}
+		notFound = ErrReplyNotFound
+	default:
+		return fmt.Errorf("unknown votable type %q", votableType)
+	}
+
+	netChange := 0
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", votableID).First(votable).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return notFound
+			}
+			return fmt.Errorf("failed to lock %s: %w", votableType, err)
+		}
+
+		var existing models.ForumVote
+		err := tx.Where("user_id = ? AND votable_type = ? AND votable_id = ?", userID, votableType, votableID).First(&existing).Error
+		hasVote := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to check vote: %w", err)
+		}
+
+		upDelta, downDelta := 0, 0
+		if hasVote {
+			if isUpvote != nil && existing.IsUpvote == *isUpvote {
+				return nil // Same vote again
+			}
+			if existing.IsUpvote {
+				upDelta--
+			} else {
+				downDelta--
+			}
+		}
+		if isUpvote != nil {
+			if *isUpvote {
+				upDelta++
 			} else {
-				database.DB.Model(&reply).UpdateColumn("downvote_count", gorm.Expr("downvote_count + 1"))
-				database.DB.Model(&reply).UpdateColumn("upvote_count", gorm.Expr("upvote_count - 1"))
+				downDelta++
 			}
 		}
-	} else {
-		return fmt.Errorf("failed to check vote: %w", err)
+
+		switch {
+		case isUpvote == nil && !hasVote:
+			return nil // Nothing to clear
+		case isUpvote == nil:
+			if err := tx.Delete(&existing).Error; err != nil {
+				return fmt.Errorf("failed to clear vote: %w", err)
+			}
+		case hasVote:
+			if err := tx.Model(&existing).Update("is_upvote", *isUpvote).Error; err != nil {
+				return fmt.Errorf("failed to update vote: %w", err)
+			}
+		default:
+			vote := models.ForumVote{
+				UserID:      userID,
+				IsUpvote:    *isUpvote,
+				VotableType: votableType,
+				VotableID:   votableID,
+			}
+			if err := tx.Create(&vote).Error; err != nil {
+				return fmt.Errorf("failed to create vote: %w", err)
+			}
+		}
+
+		if err := tx.Model(votable).UpdateColumns(map[string]interface{}{
+			"upvote_count":   gorm.Expr("upvote_count + ?", upDelta),
+			"downvote_count": gorm.Expr("downvote_count + ?", downDelta),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update vote counts: %w", err)
+		}
+
+		netChange = upDelta - downDelta
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Topic votes count towards trending, downvotes against it
+	if votableType == "topic" && netChange != 0 {
+		if err := s.engagementService.Record("topic", votableID, &userID, models.EngagementTypeVote, netChange); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
 	}
 
 	return nil
 }
 
+// RepairVoteCounts removes duplicate votes and recomputes every topic and reply
+// vote counter from forum_votes. It returns the number of duplicates removed.
+func (s *ForumService) RepairVoteCounts() (int64, error) {
+	var removed int64
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		removed, err = database.DeduplicateForumVotes(tx)
+		if err != nil {
+			return fmt.Errorf("failed to deduplicate votes: %w", err)
+		}
+
+		for _, target := range []struct {
+			table       string
+			votableType string
+		}{
+			{"forum_topics", "topic"},
+			{"forum_replies", "reply"},
+		} {
+			if err := tx.Exec(fmt.Sprintf(`
+				UPDATE %[1]s SET
+					upvote_count = (SELECT COUNT(*) FROM forum_votes v
+						WHERE v.votable_type = ? AND v.votable_id = %[1]s.id AND v.is_upvote),
+					downvote_count = (SELECT COUNT(*) FROM forum_votes v
+						WHERE v.votable_type = ? AND v.votable_id = %[1]s.id AND NOT v.is_upvote)`, target.table),
+				target.votableType, target.votableType).Error; err != nil {
+				return fmt.Errorf("failed to recompute %s vote counts: %w", target.votableType, err)
+			}
+		}
+
+		return nil
+	})
+
+	return removed, err
+}
+
 // GetUserVote gets a user's vote on a topic or reply
 func (s *ForumService) GetUserVote(userID uuid.UUID, votableType string, votableID uuid.UUID) (*models.ForumVote, error) {
 	var vote models.ForumVote
@@ -339,3 +924,163 @@ func (s *ForumService) GetUserVote(userID uuid.UUID, votableType string, votable
 	return &vote, nil
 }
 
+// SetTopicPinned pins or unpins a topic
+func (s *ForumService) SetTopicPinned(topicID uuid.UUID, pinned bool) (*models.ForumTopic, error) {
+	return s.updateTopicFlag(topicID, "is_pinned", pinned)
+}
+
+// SetTopicLocked locks or unlocks a topic for new replies
+func (s *ForumService) SetTopicLocked(topicID uuid.UUID, locked bool) (*models.ForumTopic, error) {
+	return s.updateTopicFlag(topicID, "is_locked", locked)
+}
+
+// SetTopicHidden hides a topic from listings or restores it
+func (s *ForumService) SetTopicHidden(topicID uuid.UUID, hidden bool) (*models.ForumTopic, error) {
+	return s.updateTopicFlag(topicID, "is_approved", !hidden)
+}
+
+// updateTopicFlag sets a single moderation column on a topic
+func (s *ForumService) updateTopicFlag(topicID uuid.UUID, column string, value bool) (*models.ForumTopic, error) {
+	result := database.DB.Model(&models.ForumTopic{}).Where("id = ?", topicID).Update(column, value)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to update topic: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrTopicNotFound
+	}
+
+	return s.getTopic(topicID)
+}
+
+// MoveTopicRequest represents a request to move a topic to another part of the catalog.
+// The most specific target wins: a course implies its department and university.
+type MoveTopicRequest struct {
+	CourseID     *uuid.UUID `json:"course_id,omitempty"`
+	DepartmentID *uuid.UUID `json:"department_id,omitempty"`
+	UniversityID *uuid.UUID `json:"university_id,omitempty"`
+}
+
+// MoveTopic moves a topic to a different course, department or university
+func (s *ForumService) MoveTopic(topicID uuid.UUID, req MoveTopicRequest) (*models.ForumTopic, error) {
+	var topic models.ForumTopic
+	if err := database.DB.Where("id = ?", topicID).First(&topic).Error; err != nil {
+		return nil, ErrTopicNotFound
+	}
+
+	switch {
+	case req.CourseID != nil:
+		var course models.Course
+		if err := database.DB.Where("id = ?", req.CourseID).First(&course).Error; err != nil {
+			return nil, ErrCourseNotFound
+		}
+		topic.CourseID = &course.ID
+		topic.DepartmentID = &course.DepartmentID
+		topic.UniversityID = &course.UniversityID
+	case req.DepartmentID != nil:
+		var department models.Department
+		if err := database.DB.Where("id = ?", req.DepartmentID).First(&department).Error; err != nil {
+			return nil, ErrDepartmentNotFound
+		}
+		topic.CourseID = nil
+		topic.DepartmentID = &department.ID
+		topic.UniversityID = &department.UniversityID
+	case req.UniversityID != nil:
+		var university models.University
+		if err := database.DB.Where("id = ?", req.UniversityID).First(&university).Error; err != nil {
+			return nil, ErrUniversityNotFound
+		}
+		topic.CourseID = nil
+		topic.DepartmentID = nil
+		topic.UniversityID = &university.ID
+	default:
+		return nil, ErrMoveTargetRequired
+	}
+
+	if err := database.DB.Model(&topic).Select("course_id", "department_id", "university_id").Updates(&topic).Error; err != nil {
+		return nil, fmt.Errorf("failed to move topic: %w", err)
+	}
+
+	return s.getTopic(topicID)
+}
+
+// MergeTopicRequest represents a request to merge a duplicate topic into another
+type MergeTopicRequest struct {
+	TargetTopicID uuid.UUID `json:"target_topic_id" binding:"required"`
+}
+
+// MergeTopics moves every reply of the source topic into the target topic,
+// recomputes the target's reply count and deletes the source topic
+func (s *ForumService) MergeTopics(sourceID, targetID uuid.UUID) (*models.ForumTopic, error) {
+	if sourceID == targetID {
+		return nil, ErrCannotMergeSelf
+	}
+
+	var source, target models.ForumTopic
+	if err := database.DB.Where("id = ?", sourceID).First(&source).Error; err != nil {
+		return nil, ErrTopicNotFound
+	}
+	if err := database.DB.Where("id = ?", targetID).First(&target).Error; err != nil {
+		return nil, ErrTopicNotFound
+	}
+
+	var unacceptedReplyID *uuid.UUID
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Nested replies keep their parent, which moves along with them
+		if err := tx.Model(&models.ForumReply{}).
+			Where("topic_id = ?", sourceID).
+			Update("topic_id", targetID).Error; err != nil {
+			return fmt.Errorf("failed to move replies: %w", err)
+		}
+
+		var replyCount int64
+		if err := tx.Model(&models.ForumReply{}).Where("topic_id = ?", targetID).Count(&replyCount).Error; err != nil {
+			return fmt.Errorf("failed to count replies: %w", err)
+		}
+
+		updates := map[string]interface{}{"reply_count": replyCount}
+
+		// Carry over the accepted answer unless the target already has one.
+		// Its reputation depends on who asked, so it is taken back from the
+		// source's answer and awarded again as an answer to the target.
+		if source.AcceptedReplyID != nil {
+			if err := s.clearAcceptedAnswer(tx, &source); err != nil {
+				return err
+			}
+
+			var reply models.ForumReply
+			err := tx.Where("id = ?", source.AcceptedReplyID).First(&reply).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("failed to get accepted reply: %w", err)
+			}
+
+			if err == nil && target.AcceptedReplyID == nil && target.Type == models.TopicTypeQuestion {
+				if err := s.acceptReply(tx, &target, &reply); err != nil {
+					return err
+				}
+				updates["accepted_reply_id"] = source.AcceptedReplyID
+				updates["solved_at"] = source.SolvedAt
+			} else {
+				unacceptedReplyID = source.AcceptedReplyID
+			}
+		}
+
+		if err := tx.Model(&target).UpdateColumns(updates).Error; err != nil {
+			return fmt.Errorf("failed to update merged topic: %w", err)
+		}
+
+		if err := tx.Delete(&source).Error; err != nil {
+			return fmt.Errorf("failed to delete merged topic: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if unacceptedReplyID != nil {
+		s.removeAcceptedAnswerActivity(*unacceptedReplyID)
+	}
+
+	return s.getTopic(targetID)
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/realtime"
+)
+
+var (
+	ErrNotificationNotFound    = errors.New("notification not found")
+	ErrInvalidNotificationType = errors.New("invalid notification type")
+)
+
+// NotificationService handles in-app notifications and notification preferences
+type NotificationService struct{}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService() *NotificationService {
+	return &NotificationService{}
+}
+
+// NotificationEvent describes something a user should be notified about
+type NotificationEvent struct {
+	UserID     uuid.UUID  // Recipient
+	ActorID    *uuid.UUID // User who caused the event, if any
+	Type       models.NotificationType
+	Message    string
+	EntityType string
+	EntityID   *uuid.UUID
+}
+
+// Notify stores a notification for the event's recipient. It returns nil without
+// storing anything when the recipient caused the event or has disabled the type.
+func (s *NotificationService) Notify(event NotificationEvent) (*models.Notification, error) {
+	if event.ActorID != nil && *event.ActorID == event.UserID {
+		return nil, nil
+	}
+
+	enabled, err := s.isEnabled(event.UserID, event.Type)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	notification := models.Notification{
+		UserID:     event.UserID,
+		ActorID:    event.ActorID,
+		Type:       event.Type,
+		Message:    event.Message,
+		EntityType: event.EntityType,
+		EntityID:   event.EntityID,
+	}
+
+	if err := database.DB.Create(&notification).Error; err != nil {
+		return nil, fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	realtime.Publish(realtime.UserTopic(notification.UserID), "notification.created", notification)
+
+	return &notification, nil
+}
+
+// ListNotificationsRequest represents a request to list notifications
+type ListNotificationsRequest struct {
+	Page       int  `form:"page"`
+	PageSize   int  `form:"page_size"`
+	UnreadOnly bool `form:"unread"`
+}
+
+// ListNotifications lists a user's notifications, newest first
+func (s *NotificationService) ListNotifications(userID uuid.UUID, req ListNotificationsRequest) ([]models.Notification, int64, error) {
+	query := database.DB.Model(&models.Notification{}).
+		Where("user_id = ?", userID).
+		Preload("Actor")
+
+	if req.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	if req.PageSize <= 0 {
+		req.PageSize = 20
+	}
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	offset := (req.Page - 1) * req.PageSize
+
+	var notifications []models.Notification
+	if err := query.Order("created_at DESC").Offset(offset).Limit(req.PageSize).Find(&notifications).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list notifications: %w", err)
+	}
+
+	return notifications, total, nil
+}
+
+// MarkRead marks one of the user's notifications as read
+func (s *NotificationService) MarkRead(notificationID, userID uuid.UUID) (*models.Notification, error) {
+	var notification models.Notification
+	if err := database.DB.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, fmt.Errorf("failed to get notification: %w", err)
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		notification.ReadAt = &now
+		if err := database.DB.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, fmt.Errorf("failed to mark notification as read: %w", err)
+		}
+	}
+
+	return &notification, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read
+func (s *NotificationService) MarkAllRead(userID uuid.UUID) (int64, error) {
+	result := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// UnreadCount returns the number of unread notifications for a user
+func (s *NotificationService) UnreadCount(userID uuid.UUID) (int64, error) {
+	var count int64
+	if err := database.DB.Model(&models.Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetPreferences returns whether each notification type is enabled for a user
+func (s *NotificationService) GetPreferences(userID uuid.UUID) (map[models.NotificationType]bool, error) {
+	var stored []models.NotificationPreference
+	if err := database.DB.Where("user_id = ?", userID).Find(&stored).Error; err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+
+	preferences := make(map[models.NotificationType]bool, len(models.NotificationTypes))
+	for _, notificationType := range models.NotificationTypes {
+		preferences[notificationType] = true
+	}
+	for _, preference := range stored {
+		preferences[preference.Type] = preference.Enabled
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences enables or disables notification types for a user.
+// Types not present in the request are left unchanged.
+func (s *NotificationService) UpdatePreferences(userID uuid.UUID, updates map[models.NotificationType]bool) (map[models.NotificationType]bool, error) {
+	for notificationType := range updates {
+		if !isNotificationType(notificationType) {
+			return nil, ErrInvalidNotificationType
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for notificationType, enabled := range updates {
+			preference := models.NotificationPreference{
+				UserID:  userID,
+				Type:    notificationType,
+				Enabled: enabled,
+			}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}},
+				DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+			}).Create(&preference).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update notification preferences: %w", err)
+	}
+
+	return s.GetPreferences(userID)
+}
+
+// isEnabled checks if a user wants notifications of the given type
+func (s *NotificationService) isEnabled(userID uuid.UUID, notificationType models.NotificationType) (bool, error) {
+	var preference models.NotificationPreference
+	if err := database.DB.Where("user_id = ? AND type = ?", userID, notificationType).First(&preference).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return true, nil
+		}
+		return false, fmt.Errorf("failed to get notification preference: %w", err)
+	}
+
+	return preference.Enabled, nil
+}
+
+func isNotificationType(notificationType models.NotificationType) bool {
+	for _, t := range models.NotificationTypes {
+		if t == notificationType {
+			return true
+		}
+	}
+	return false
+}
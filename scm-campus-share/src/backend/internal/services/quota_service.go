@@ -0,0 +1,281 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/campus-share/backend/internal/config"
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var (
+	ErrStorageQuotaExceeded = errors.New("storage quota exceeded")
+	ErrFileQuotaExceeded    = errors.New("file quota exceeded")
+	ErrInvalidQuota         = errors.New("quota must not be negative")
+	ErrInvalidRole          = errors.New("invalid role")
+)
+
+// Where a user's quota comes from
+const (
+	QuotaSourceRole       = "role"
+	QuotaSourceUniversity = "university"
+	QuotaSourceUser       = "user"
+)
+
+// usageSQL adds up the files a user has stored: every version of their resources,
+// or the resource's own file for resources uploaded before versions were kept,
+// and every attached file. Files shared with identical uploads count for each.
+const usageSQL = `
+	SELECT COUNT(*) AS files, COALESCE(SUM(file_size), 0) AS used_bytes FROM (
+		SELECT v.file_size FROM resource_versions v
+		JOIN resources r ON r.id = v.resource_id AND r.deleted_at IS NULL
+		WHERE r.user_id = ?
+		UNION ALL
+		SELECT r.file_size FROM resources r
+		WHERE r.user_id = ? AND r.deleted_at IS NULL
+		  AND NOT EXISTS (SELECT 1 FROM resource_versions v WHERE v.resource_id = r.id)
+		UNION ALL
+		SELECT f.file_size FROM resource_files f
+		JOIN resources r ON r.id = f.resource_id AND r.deleted_at IS NULL
+		WHERE r.user_id = ?
+	) stored`
+
+// resourceUsageSQL adds up the files one resource stores, counted as in usageSQL
+const resourceUsageSQL = `
+	SELECT COUNT(*) AS files, COALESCE(SUM(file_size), 0) AS used_bytes FROM (
+		SELECT v.file_size FROM resource_versions v WHERE v.resource_id = ?
+		UNION ALL
+		SELECT r.file_size FROM resources r
+		WHERE r.id = ? AND NOT EXISTS (SELECT 1 FROM resource_versions v WHERE v.resource_id = r.id)
+		UNION ALL
+		SELECT f.file_size FROM resource_files f WHERE f.resource_id = ?
+	) stored`
+
+// QuotaService tracks how much users store and enforces their storage quotas
+type QuotaService struct {
+	config *config.QuotaConfig
+}
+
+// NewQuotaService creates a new quota service
+func NewQuotaService(cfg *config.QuotaConfig) *QuotaService {
+	return &QuotaService{config: cfg}
+}
+
+// StorageUsage is what a user stores against their quota. A limit of 0 means unlimited.
+type StorageUsage struct {
+	UsedBytes   int64  `json:"used_bytes"`
+	Files       int64  `json:"files"`
+	MaxBytes    int64  `json:"max_bytes"`
+	MaxFiles    int64  `json:"max_files"`
+	QuotaSource string `json:"quota_source"` // "role", "university" or "user"
+}
+
+// GetUsage returns a user's storage usage and quota
+func (s *QuotaService) GetUsage(userID uuid.UUID) (*StorageUsage, error) {
+	var user models.User
+	if err := database.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return s.usage(database.DB, &user)
+}
+
+// usage returns a user's storage usage and quota, reading what they store with db
+func (s *QuotaService) usage(db *gorm.DB, user *models.User) (*StorageUsage, error) {
+	usage, err := s.quotaFor(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Raw(usageSQL, user.ID, user.ID, user.ID).Scan(usage).Error; err != nil {
+		return nil, fmt.Errorf("failed to get storage usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// ResourceUsage returns the bytes and number of files a resource stores,
+// whether or not it is deleted
+func (s *QuotaService) ResourceUsage(resourceID uuid.UUID) (int64, int64, error) {
+	var usage StorageUsage
+	if err := database.DB.Raw(resourceUsageSQL, resourceID, resourceID, resourceID).Scan(&usage).Error; err != nil {
+		return 0, 0, fmt.Errorf("failed to get resource storage: %w", err)
+	}
+	return usage.UsedBytes, usage.Files, nil
+}
+
+// CheckUpload returns an error if storing bytes more in files more files would
+// take the user over their quota. It must run in the transaction that records
+// the files: the user's quota stays locked until it ends, so parallel uploads
+// are checked one after the other, each counting those before it.
+func (s *QuotaService) CheckUpload(tx *gorm.DB, userID uuid.UUID, bytes int64, files int64) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", userID.String()).Error; err != nil {
+		return fmt.Errorf("failed to lock storage quota: %w", err)
+	}
+
+	var user models.User
+	if err := tx.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	usage, err := s.usage(tx, &user)
+	if err != nil {
+		return err
+	}
+
+	if usage.MaxFiles > 0 && files > 0 && usage.Files+files > usage.MaxFiles {
+		return ErrFileQuotaExceeded
+	}
+	if usage.MaxBytes > 0 && bytes > 0 && usage.UsedBytes+bytes > usage.MaxBytes {
+		return ErrStorageQuotaExceeded
+	}
+
+	return nil
+}
+
+// quotaFor works out a user's quota: their own if an admin set one, otherwise
+// their university's for their role, otherwise their role's default
+func (s *QuotaService) quotaFor(user *models.User) (*StorageUsage, error) {
+	maxMB, maxFiles := s.roleDefaults(user.Role)
+	usage := &StorageUsage{QuotaSource: QuotaSourceRole}
+
+	if user.UniversityID != nil {
+		var quota models.UniversityQuota
+		err := database.DB.Where("university_id = ? AND role = ?", user.UniversityID, user.Role).First(&quota).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to get university quota: %w", err)
+		}
+		if err == nil {
+			if quota.MaxMB != nil {
+				maxMB = *quota.MaxMB
+				usage.QuotaSource = QuotaSourceUniversity
+			}
+			if quota.MaxFiles != nil {
+				maxFiles = *quota.MaxFiles
+				usage.QuotaSource = QuotaSourceUniversity
+			}
+		}
+	}
+
+	if user.QuotaMB != nil {
+		maxMB = *user.QuotaMB
+		usage.QuotaSource = QuotaSourceUser
+	}
+	if user.QuotaFiles != nil {
+		maxFiles = *user.QuotaFiles
+		usage.QuotaSource = QuotaSourceUser
+	}
+
+	usage.MaxBytes = int64(maxMB) * 1024 * 1024
+	usage.MaxFiles = int64(maxFiles)
+	return usage, nil
+}
+
+func (s *QuotaService) roleDefaults(role models.UserRole) (int, int) {
+	switch role {
+	case models.RoleAdmin:
+		return s.config.AdminMB, s.config.AdminFiles
+	case models.RoleModerator:
+		return s.config.ModeratorMB, s.config.ModeratorFiles
+	default:
+		return s.config.StudentMB, s.config.StudentFiles
+	}
+}
+
+// QuotaRequest sets a storage quota. A nil limit removes the override.
+type QuotaRequest struct {
+	MaxMB    *int `json:"max_mb"`
+	MaxFiles *int `json:"max_files"`
+}
+
+func (r QuotaRequest) validate() error {
+	if (r.MaxMB != nil && *r.MaxMB < 0) || (r.MaxFiles != nil && *r.MaxFiles < 0) {
+		return ErrInvalidQuota
+	}
+	return nil
+}
+
+// SetUserQuota gives a user their own storage quota, replacing any set before
+func (s *QuotaService) SetUserQuota(userID uuid.UUID, req QuotaRequest) (*StorageUsage, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	result := database.DB.Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"quota_mb":    req.MaxMB,
+			"quota_files": req.MaxFiles,
+		})
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to set quota: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	return s.GetUsage(userID)
+}
+
+// ListUniversityQuotas lists the quotas a university overrides
+func (s *QuotaService) ListUniversityQuotas(universityID uuid.UUID) ([]models.UniversityQuota, error) {
+	var quotas []models.UniversityQuota
+	if err := database.DB.Where("university_id = ?", universityID).Order("role").Find(&quotas).Error; err != nil {
+		return nil, fmt.Errorf("failed to list university quotas: %w", err)
+	}
+	return quotas, nil
+}
+
+// SetUniversityQuota overrides the quota of a role at a university. Setting
+// both limits to nil removes the override.
+func (s *QuotaService) SetUniversityQuota(universityID uuid.UUID, role models.UserRole, req QuotaRequest) (*models.UniversityQuota, error) {
+	if role != models.RoleStudent && role != models.RoleModerator && role != models.RoleAdmin {
+		return nil, ErrInvalidRole
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	var university models.University
+	if err := database.DB.Where("id = ?", universityID).First(&university).Error; err != nil {
+		return nil, ErrUniversityNotFound
+	}
+
+	if req.MaxMB == nil && req.MaxFiles == nil {
+		if err := database.DB.Where("university_id = ? AND role = ?", universityID, role).
+			Delete(&models.UniversityQuota{}).Error; err != nil {
+			return nil, fmt.Errorf("failed to remove university quota: %w", err)
+		}
+		return nil, nil
+	}
+
+	quota := models.UniversityQuota{
+		UniversityID: universityID,
+		Role:         role,
+		MaxMB:        req.MaxMB,
+		MaxFiles:     req.MaxFiles,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "university_id"}, {Name: "role"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_mb", "max_files", "updated_at"}),
+	}).Create(&quota).Error; err != nil {
+		return nil, fmt.Errorf("failed to set university quota: %w", err)
+	}
+
+	if err := database.DB.Where("university_id = ? AND role = ?", universityID, role).First(&quota).Error; err != nil {
+		return nil, fmt.Errorf("failed to get university quota: %w", err)
+	}
+
+	return &quota, nil
+}
@@ -12,11 +12,15 @@ import (
 )
 
 // RatingService handles rating-related operations
-type RatingService struct{}
+type RatingService struct {
+	activityService *ActivityService
+}
 
 // NewRatingService creates a new rating service
 func NewRatingService() *RatingService {
-	return &RatingService{}
+	return &RatingService{
+		activityService: NewActivityService(),
+	}
 }
 
 // CreateRatingRequest represents a request to create/update a rating
@@ -46,6 +50,10 @@ func (s *RatingService) CreateOrUpdateRating(resourceID, userID uuid.UUID, req C
 		if err := database.DB.Create(&rating).Error; err != nil {
 			return nil, fmt.Errorf("failed to create rating: %w", err)
 		}
+
+		if err := s.activityService.Record(userID, models.ActivityTypeResourceRated, "rating", rating.ID, resource.CourseID); err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
 	} else if err != nil {
 		return nil, fmt.Errorf("failed to check rating: %w", err)
 	} else {
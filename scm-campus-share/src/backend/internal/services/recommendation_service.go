@@ -1,29 +1,81 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/config"
 	"github.com/campus-share/backend/internal/database"
 	"github.com/campus-share/backend/internal/models"
 )
 
+// Collaborative filtering tuning
+const (
+	// similarityMinSupport is how many users must share two resources before they count as similar
+	similarityMinSupport = 2
+	// similarityNeighbors is how many similar resources are kept per resource
+	similarityNeighbors = 50
+	// recommendationSeedLimit is how many of a user's most recent interactions seed recommendations
+	recommendationSeedLimit = 100
+	// popularRecommendationEvery is how often a popular resource is blended into
+	// collaborative recommendations: one slot in this many
+	popularRecommendationEvery = 4
+)
+
+// interactionsSQL lists weighted user-resource interactions. A bookmark is a stronger
+// signal than a download; ratings of 3 to 5 count 1 to 3 and lower ratings are ignored.
+const interactionsSQL = `
+	SELECT user_id, resource_id, 'downloaded' AS kind, 1.0 AS weight, created_at FROM resource_downloads
+	UNION ALL
+	SELECT user_id, resource_id, 'saved' AS kind, 2.0 AS weight, created_at FROM bookmarks WHERE deleted_at IS NULL
+	UNION ALL
+	SELECT user_id, resource_id, 'rated' AS kind, value - 2.0 AS weight, created_at FROM ratings WHERE deleted_at IS NULL AND value >= 3`
+
+// Recommendation sources
+const (
+	RecommendationSourceCollaborative = "collaborative"
+	RecommendationSourcePopular       = "popular"
+)
+
+// RecommendedResource is a resource with the reason it was recommended
+type RecommendedResource struct {
+	models.Resource
+	Reason string  `json:"reason"`
+	Source string  `json:"source"`
+	Score  float64 `json:"score"`
+}
+
 // RecommendationService handles resource recommendations
-type RecommendationService struct{}
+type RecommendationService struct {
+	similar *config.SimilarConfig
+}
 
 // NewRecommendationService creates a new recommendation service
-func NewRecommendationService() *RecommendationService {
-	return &RecommendationService{}
+func NewRecommendationService(cfg *config.SimilarConfig) *RecommendationService {
+	return &RecommendationService{
+		similar: cfg,
+	}
 }
 
-// GetSimilarResources returns resources similar to the given resource
-func (s *RecommendationService) GetSimilarResources(resourceID uuid.UUID, limit int) ([]models.Resource, error) {
-	// Get the target resource
-	var targetResource models.Resource
-	if err := database.DB.Where("id = ?", resourceID).First(&targetResource).Error; err != nil {
-		return nil, fmt.Errorf("resource not found: %w", err)
-	}
+// similarCandidateLimit caps how many candidates are scored for similar resources
+const similarCandidateLimit = 500
+
+// SimilarResource is a resource with how similar it is to another resource
+type SimilarResource struct {
+	models.Resource
+	Score float64 `json:"score"`
+}
 
+// GetSimilarResources returns resources similar to the given resource, scored by
+// overlap of their tags, similarity of title and description, and closeness in the
+// catalog. Only resources the user may see are considered; userID is nil for
+// anonymous users. Resources sharing nothing with the given resource are not returned.
+func (s *RecommendationService) GetSimilarResources(resourceID uuid.UUID, userID *uuid.UUID, limit int) ([]SimilarResource, error) {
 	if limit <= 0 {
 		limit = 5
 	}
@@ -31,60 +83,160 @@ func (s *RecommendationService) GetSimilarResources(resourceID uuid.UUID, limit
 		limit = 20
 	}
 
-	var resources []models.Resource
-	query := database.DB.Model(&models.Resource{}).
-		Preload("User").
-		Preload("University").
-		Preload("Course").
-		Preload("Tags.Tag").
-		Where("id != ? AND is_approved = ?", resourceID, true)
+	// Get the target resource
+	var target models.Resource
+	if err := database.DB.Preload("Tags").Where("id = ?", resourceID).First(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get resource: %w", err)
+	}
 
-	// Find similar resources by:
-	// 1. Same course (highest priority)
-	if targetResource.CourseID != nil {
-		query = query.Where("course_id = ?", targetResource.CourseID)
-	} else if targetResource.DepartmentID != nil {
-		// 2. Same department
-		query = query.Where("department_id = ?", targetResource.DepartmentID)
-	} else if targetResource.UniversityID != nil {
-		// 3. Same university
-		query = query.Where("university_id = ?", targetResource.UniversityID)
+	// Owners always see their own resources; others only approved ones they may see
+	if userID == nil || target.UserID != *userID {
+		var visible int64
+		if err := database.DB.Model(&models.Resource{}).
+			Where("id = ? AND is_approved = ?", resourceID, true).
+			Scopes(visibleResources(userID)).
+			Count(&visible).Error; err != nil {
+			return nil, fmt.Errorf("failed to get resource: %w", err)
+		}
+		if visible == 0 {
+			return nil, ErrResourceNotFound
+		}
 	}
 
-	// Order by popularity and recency
-	query = query.Order("download_count DESC, view_count DESC, created_at DESC").
-		Limit(limit)
+	candidates, err := s.similarCandidates(&target, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []SimilarResource{}, nil
+	}
+	markBookmarked(candidates, userID)
 
-	if err := query.Find(&resources).Error; err != nil {
-		return nil, fmt.Errorf("failed to get similar resources: %w", err)
+	targetTags := tagSet(&target)
+	documents := map[uuid.UUID][]string{target.ID: tokenize(target.Title + " " + target.Description)}
+	for _, candidate := range candidates {
+		documents[candidate.ID] = tokenize(candidate.Title + " " + candidate.Description)
 	}
+	index := newTFIDFIndex(documents)
 
-	// If we don't have enough results, fill with popular resources
-	if len(resources) < limit {
-		var additionalResources []models.Resource
-		excludeIDs := []uuid.UUID{resourceID}
-		for _, r := range resources {
-			excludeIDs = append(excludeIDs, r.ID)
+	similar := make([]SimilarResource, 0, len(candidates))
+	for _, candidate := range candidates {
+		score := s.similar.TagWeight*jaccard(targetTags, tagSet(&candidate)) +
+			s.similar.TextWeight*index.cosine(target.ID, candidate.ID) +
+			s.similar.CatalogWeight*catalogProximity(&target, &candidate)
+		if score <= 0 {
+			continue
 		}
+		similar = append(similar, SimilarResource{Resource: candidate, Score: score})
+	}
 
-		database.DB.Model(&models.Resource{}).
-			Preload("User").
-			Preload("University").
-			Preload("Course").
-			Preload("Tags.Tag").
-			Where("id NOT IN ? AND is_approved = ?", excludeIDs, true).
-			Order("download_count DESC, view_count DESC").
-			Limit(limit - len(resources)).
-			Find(&additionalResources)
+	sort.SliceStable(similar, func(i, j int) bool {
+		if similar[i].Score != similar[j].Score {
+			return similar[i].Score > similar[j].Score
+		}
+		return similar[i].DownloadCount > similar[j].DownloadCount
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
 
-		resources = append(resources, additionalResources...)
+	return similar, nil
+}
+
+// similarCandidates returns visible resources that share a tag, a catalog entry
+// or a title keyword with the target
+func (s *RecommendationService) similarCandidates(target *models.Resource, userID *uuid.UUID) ([]models.Resource, error) {
+	var conditions []string
+	var args []interface{}
+
+	if len(target.Tags) > 0 {
+		tagIDs := make([]uuid.UUID, len(target.Tags))
+		for i, tag := range target.Tags {
+			tagIDs[i] = tag.TagID
+		}
+		conditions = append(conditions, "id IN (SELECT resource_id FROM resource_tags WHERE tag_id IN ?)")
+		args = append(args, tagIDs)
+	}
+
+	// Same course or department; a whole university is only considered when
+	// the resource is not filed any more precisely
+	if target.CourseID != nil {
+		conditions = append(conditions, "course_id = ?")
+		args = append(args, target.CourseID)
+	}
+	if target.DepartmentID != nil {
+		conditions = append(conditions, "department_id = ?")
+		args = append(args, target.DepartmentID)
+	}
+	if target.CourseID == nil && target.DepartmentID == nil && target.UniversityID != nil {
+		conditions = append(conditions, "university_id = ?")
+		args = append(args, target.UniversityID)
+	}
+
+	for _, keyword := range keywords(target.Title, 5) {
+		conditions = append(conditions, "title ILIKE ?")
+		args = append(args, "%"+keyword+"%")
+	}
+
+	if len(conditions) == 0 {
+		return nil, nil
+	}
+
+	var candidates []models.Resource
+	if err := database.DB.
+		Preload("User").
+		Preload("University").
+		Preload("Course").
+		Preload("Tags.Tag").
+		Where("id != ? AND is_approved = ?", target.ID, true).
+		Where(strings.Join(conditions, " OR "), args...).
+		Scopes(visibleResources(userID)).
+		Order("download_count DESC, view_count DESC, created_at DESC").
+		Limit(similarCandidateLimit).
+		Find(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to get similar resources: %w", err)
+	}
+
+	return candidates, nil
+}
+
+// tagSet returns the IDs of a resource's tags
+func tagSet(resource *models.Resource) map[uuid.UUID]bool {
+	tags := make(map[uuid.UUID]bool, len(resource.Tags))
+	for _, tag := range resource.Tags {
+		tags[tag.TagID] = true
 	}
+	return tags
+}
+
+// catalogProximity scores how close two resources are filed in the catalog
+func catalogProximity(a, b *models.Resource) float64 {
+	switch {
+	case a.CourseID != nil && b.CourseID != nil && *a.CourseID == *b.CourseID:
+		return 1
+	case a.DepartmentID != nil && b.DepartmentID != nil && *a.DepartmentID == *b.DepartmentID:
+		return 0.6
+	case a.UniversityID != nil && b.UniversityID != nil && *a.UniversityID == *b.UniversityID:
+		return 0.3
+	}
+	return 0
+}
 
-	return resources, nil
+// recommendationSeed is a resource the user interacted with
+type recommendationSeed struct {
+	ResourceID uuid.UUID
+	Kind       string
+	Weight     float64
 }
 
-// GetRecommendedForUser returns resources recommended for a user
-func (s *RecommendationService) GetRecommendedForUser(userID uuid.UUID, limit int) ([]models.Resource, error) {
+// GetRecommendedForUser returns resources recommended for a user: resources similar to
+// what the user downloaded, bookmarked or rated, blended with popular resources from
+// the user's department or university. Every fourth slot goes to a popular resource,
+// and popular resources fill the slots collaborative filtering cannot.
+func (s *RecommendationService) GetRecommendedForUser(userID uuid.UUID, limit int) ([]RecommendedResource, error) {
 	if limit <= 0 {
 		limit = 10
 	}
@@ -98,19 +250,224 @@ func (s *RecommendationService) GetRecommendedForUser(userID uuid.UUID, limit in
 		return nil, fmt.Errorf("user not found: %w", err)
 	}
 
+	seeds, err := s.getSeeds(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resources the user already has are never recommended
+	excludeIDs := []uuid.UUID{}
+	for _, seed := range seeds {
+		excludeIDs = append(excludeIDs, seed.ResourceID)
+	}
+
+	collaborative, err := s.collaborativeRecommendations(userID, seeds, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range collaborative {
+		excludeIDs = append(excludeIDs, r.ID)
+	}
+
+	popular, err := s.popularRecommendations(&user, excludeIDs, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return blendRecommendations(collaborative, popular, limit), nil
+}
+
+// blendRecommendations interleaves collaborative and popular recommendations,
+// each in their own order, giving one slot in popularRecommendationEvery to a
+// popular one. Whichever runs out first leaves its slots to the other.
+func blendRecommendations(collaborative, popular []RecommendedResource, limit int) []RecommendedResource {
+	blended := make([]RecommendedResource, 0, limit)
+	for len(blended) < limit && (len(collaborative) > 0 || len(popular) > 0) {
+		popularSlot := (len(blended)+1)%popularRecommendationEvery == 0
+		if len(collaborative) == 0 || (popularSlot && len(popular) > 0) {
+			blended = append(blended, popular[0])
+			popular = popular[1:]
+			continue
+		}
+		blended = append(blended, collaborative[0])
+		collaborative = collaborative[1:]
+	}
+	return blended
+}
+
+// notAlreadyHad leaves out resources the user downloaded or bookmarked at any
+// time, not only those among the seeds
+func notAlreadyHad(userID uuid.UUID) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("NOT EXISTS (SELECT 1 FROM resource_downloads d WHERE d.resource_id = resources.id AND d.user_id = ?)", userID).
+			Where("NOT EXISTS (SELECT 1 FROM bookmarks b WHERE b.resource_id = resources.id AND b.user_id = ? AND b.deleted_at IS NULL)", userID)
+	}
+}
+
+// getSeeds returns the user's most recent interactions, one per resource,
+// labelled with the strongest kind of interaction
+func (s *RecommendationService) getSeeds(userID uuid.UUID) ([]recommendationSeed, error) {
+	var rows []struct {
+		ResourceID uuid.UUID
+		Kind       string
+		Weight     float64
+	}
+	if err := database.DB.Raw(`
+		SELECT resource_id, kind, weight FROM (`+interactionsSQL+`) i
+		WHERE user_id = ?
+		  AND resource_id IN (
+			SELECT resource_id FROM (`+interactionsSQL+`) recent
+			WHERE user_id = ?
+			GROUP BY resource_id
+			ORDER BY MAX(created_at) DESC
+			LIMIT ?
+		  )`, userID, userID, recommendationSeedLimit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user interactions: %w", err)
+	}
+
+	// Saving says more than rating, which says more than downloading
+	priority := map[string]int{"downloaded": 1, "rated": 2, "saved": 3}
+
+	seedsByResource := make(map[uuid.UUID]*recommendationSeed)
+	for _, row := range rows {
+		seed, ok := seedsByResource[row.ResourceID]
+		if !ok {
+			seed = &recommendationSeed{ResourceID: row.ResourceID}
+			seedsByResource[row.ResourceID] = seed
+		}
+		seed.Weight += row.Weight
+		if priority[row.Kind] > priority[seed.Kind] {
+			seed.Kind = row.Kind
+		}
+	}
+
+	seeds := make([]recommendationSeed, 0, len(seedsByResource))
+	for _, seed := range seedsByResource {
+		seeds = append(seeds, *seed)
+	}
+
+	return seeds, nil
+}
+
+// collaborativeRecommendations scores resources by their precomputed similarity to the seeds
+func (s *RecommendationService) collaborativeRecommendations(userID uuid.UUID, seeds []recommendationSeed, limit int) ([]RecommendedResource, error) {
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+
+	seedIDs := make([]uuid.UUID, len(seeds))
+	seedsByResource := make(map[uuid.UUID]recommendationSeed, len(seeds))
+	for i, seed := range seeds {
+		seedIDs[i] = seed.ResourceID
+		seedsByResource[seed.ResourceID] = seed
+	}
+
+	var similarities []models.ResourceSimilarity
+	if err := database.DB.
+		Where("resource_id IN ? AND similar_resource_id NOT IN ?", seedIDs, seedIDs).
+		Find(&similarities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get similar resources: %w", err)
+	}
+
+	type candidate struct {
+		score        float64
+		bestSeed     uuid.UUID
+		bestSeedGain float64
+	}
+	candidates := make(map[uuid.UUID]*candidate)
+	for _, similarity := range similarities {
+		gain := seedsByResource[similarity.ResourceID].Weight * similarity.Score
+
+		c, ok := candidates[similarity.SimilarResourceID]
+		if !ok {
+			c = &candidate{}
+			candidates[similarity.SimilarResourceID] = c
+		}
+		c.score += gain
+		if gain > c.bestSeedGain {
+			c.bestSeed = similarity.ResourceID
+			c.bestSeedGain = gain
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	candidateIDs := make([]uuid.UUID, 0, len(candidates))
+	for id := range candidates {
+		candidateIDs = append(candidateIDs, id)
+	}
+
+	var resources []models.Resource
+	if err := database.DB.
+		Preload("User").
+		Preload("University").
+		Preload("Course").
+		Preload("Tags.Tag").
+		Where("id IN ? AND is_approved = ? AND user_id != ?", candidateIDs, true, userID).
+		Scopes(visibleResources(&userID), notAlreadyHad(userID)).
+		Find(&resources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get recommendations: %w", err)
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		return candidates[resources[i].ID].score > candidates[resources[j].ID].score
+	})
+	if len(resources) > limit {
+		resources = resources[:limit]
+	}
+
+	// Titles of the seeds used in explanations
+	var seedResources []models.Resource
+	if err := database.DB.Select("id", "title").Where("id IN ?", seedIDs).Find(&seedResources).Error; err != nil {
+		return nil, fmt.Errorf("failed to get seed resources: %w", err)
+	}
+	titles := make(map[uuid.UUID]string, len(seedResources))
+	for _, r := range seedResources {
+		titles[r.ID] = r.Title
+	}
+
+	recommendations := make([]RecommendedResource, len(resources))
+	for i, resource := range resources {
+		c := candidates[resource.ID]
+		seed := seedsByResource[c.bestSeed]
+		recommendations[i] = RecommendedResource{
+			Resource: resource,
+			Reason:   fmt.Sprintf("Because you %s \"%s\"", seed.Kind, titles[seed.ResourceID]),
+			Source:   RecommendationSourceCollaborative,
+			Score:    c.score,
+		}
+	}
+
+	return recommendations, nil
+}
+
+// popularRecommendations is the content-based source: the most downloaded resources
+// in the user's department or university
+func (s *RecommendationService) popularRecommendations(user *models.User, excludeIDs []uuid.UUID, limit int) ([]RecommendedResource, error) {
 	var resources []models.Resource
 	query := database.DB.Model(&models.Resource{}).
 		Preload("User").
 		Preload("University").
 		Preload("Course").
 		Preload("Tags.Tag").
-		Where("is_approved = ? AND user_id != ?", true, userID)
+		Where("is_approved = ? AND user_id != ?", true, user.ID).
+		Scopes(visibleResources(&user.ID), notAlreadyHad(user.ID))
+
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
 
 	// Recommend based on user's university/department
+	reason := "Popular on Campus Share"
 	if user.DepartmentID != nil {
 		query = query.Where("department_id = ?", user.DepartmentID)
+		reason = "Popular in your department"
 	} else if user.UniversityID != nil {
 		query = query.Where("university_id = ?", user.UniversityID)
+		reason = "Popular at your university"
 	}
 
 	// Order by popularity
@@ -121,6 +478,71 @@ func (s *RecommendationService) GetRecommendedForUser(userID uuid.UUID, limit in
 		return nil, fmt.Errorf("failed to get recommendations: %w", err)
 	}
 
-	return resources, nil
+	recommendations := make([]RecommendedResource, len(resources))
+	for i, resource := range resources {
+		recommendations[i] = RecommendedResource{
+			Resource: resource,
+			Reason:   reason,
+			Source:   RecommendationSourcePopular,
+		}
+	}
+
+	return recommendations, nil
 }
 
+// ComputeSimilarities rebuilds the item-to-item similarity table from downloads,
+// bookmarks and ratings. Two resources are similar when the same users interacted
+// with both; the score is the cosine similarity of their weighted interactions.
+// It is meant to run periodically and returns the number of pairs stored.
+func (s *RecommendationService) ComputeSimilarities() (int64, error) {
+	var stored int64
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM resource_similarities").Error; err != nil {
+			return err
+		}
+
+		result := tx.Exec(`
+			WITH interactions AS (
+				SELECT user_id, resource_id, SUM(weight) AS weight
+				FROM (`+interactionsSQL+`) i
+				GROUP BY user_id, resource_id
+			),
+			norms AS (
+				SELECT resource_id, SQRT(SUM(weight * weight)) AS norm
+				FROM interactions
+				GROUP BY resource_id
+			),
+			pairs AS (
+				SELECT a.resource_id, b.resource_id AS similar_resource_id,
+				       SUM(a.weight * b.weight) AS dot, COUNT(*) AS support
+				FROM interactions a
+				JOIN interactions b ON a.user_id = b.user_id AND a.resource_id <> b.resource_id
+				GROUP BY a.resource_id, b.resource_id
+				HAVING COUNT(*) >= ?
+			),
+			ranked AS (
+				SELECT p.resource_id, p.similar_resource_id, p.support,
+				       p.dot / (na.norm * nb.norm) AS score,
+				       ROW_NUMBER() OVER (PARTITION BY p.resource_id ORDER BY p.dot / (na.norm * nb.norm) DESC) AS rank
+				FROM pairs p
+				JOIN norms na ON na.resource_id = p.resource_id
+				JOIN norms nb ON nb.resource_id = p.similar_resource_id
+			)
+			INSERT INTO resource_similarities (id, resource_id, similar_resource_id, score, support, computed_at)
+			SELECT gen_random_uuid(), resource_id, similar_resource_id, score, support, NOW()
+			FROM ranked
+			WHERE rank <= ?`, similarityMinSupport, similarityNeighbors)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		stored = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute resource similarities: %w", err)
+	}
+
+	return stored, nil
+}
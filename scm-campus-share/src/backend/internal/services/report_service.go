@@ -17,11 +17,15 @@ var (
 )
 
 // ReportService handles report-related operations
-type ReportService struct{}
+type ReportService struct {
+	notificationService *NotificationService
+}
 
 // NewReportService creates a new report service
 func NewReportService() *ReportService {
-	return &ReportService{}
+	return &ReportService{
+		notificationService: NewNotificationService(),
+	}
 }
 
 // CreateReportRequest represents a request to create a report
@@ -160,7 +164,7 @@ func (s *ReportService) ApproveReport(reportID, adminID uuid.UUID, req ApproveRe
 		return nil, fmt.Errorf("failed to approve report: %w", err)
 	}
 
-	return s.GetReportByID(reportID)
+	return s.resolveReport(reportID)
 }
 
 // RejectReportRequest represents a request to reject a report
@@ -185,6 +189,26 @@ func (s *ReportService) RejectReport(reportID, adminID uuid.UUID, req RejectRepo
 		return nil, fmt.Errorf("failed to reject report: %w", err)
 	}
 
-	return s.GetReportByID(reportID)
+	return s.resolveReport(reportID)
 }
 
+// resolveReport reloads a reviewed report and tells the reporter about the decision
+func (s *ReportService) resolveReport(reportID uuid.UUID) (*models.Report, error) {
+	report, err := s.GetReportByID(reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Reviewers stay anonymous to the reporter
+	if _, err := s.notificationService.Notify(NotificationEvent{
+		UserID:     report.UserID,
+		Type:       models.NotificationTypeReportResolved,
+		Message:    fmt.Sprintf("Your report on \"%s\" was %s", report.Resource.Title, report.Status),
+		EntityType: "report",
+		EntityID:   &report.ID,
+	}); err != nil {
+		fmt.Printf("Warning: failed to send report notification: %v\n", err)
+	}
+
+	return report, nil
+}
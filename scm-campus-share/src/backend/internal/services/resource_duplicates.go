@@ -0,0 +1,394 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+var ErrCannotMergeResourceSelf = errors.New("cannot merge a resource into itself")
+
+// duplicateLimit caps how many existing copies of an upload are suggested
+const duplicateLimit = 5
+
+// liveFilesSQL lists the stored file of every resource, version and attachment
+// whose files have not been purged. Deleted resources keep their files until
+// the storage garbage collector purges them. Identical uploads share one stored
+// file, which may only be deleted once nothing here uses it.
+const liveFilesSQL = `
+	SELECT s3_key, content_hash FROM resources WHERE files_purged_at IS NULL
+	UNION ALL
+	SELECT v.s3_key, v.content_hash FROM resource_versions v
+	JOIN resources r ON r.id = v.resource_id AND r.files_purged_at IS NULL
+	UNION ALL
+	SELECT f.s3_key, f.content_hash FROM resource_files f
+	JOIN resources r ON r.id = f.resource_id AND r.files_purged_at IS NULL`
+
+// hashUpload returns the SHA-256 of an uploaded file as hex
+func hashUpload(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// lockContent holds a lock on a file content until the transaction ends, so an
+// upload that shares a stored file and a release of that file cannot overlap.
+// Uploads without a recorded hash are never shared and need no lock.
+func lockContent(tx *gorm.DB, contentHash string) error {
+	if contentHash == "" {
+		return nil
+	}
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", contentHash).Error; err != nil {
+		return fmt.Errorf("failed to lock stored file: %w", err)
+	}
+	return nil
+}
+
+// storeUpload stores an uploaded file under key, unless a file with the same
+// content is already stored, in which case that one is shared. It returns the
+// key the content is stored under and its SHA-256. It must run in the
+// transaction that records the file, which keeps the shared file from being
+// released before the record is committed.
+func (s *ResourceService) storeUpload(tx *gorm.DB, key string, header *multipart.FileHeader, fileType string) (string, string, error) {
+	contentHash, err := hashUpload(header)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := lockContent(tx, contentHash); err != nil {
+		return "", "", err
+	}
+
+	var existing string
+	if err := tx.Raw(`SELECT s3_key FROM (`+liveFilesSQL+`) f WHERE content_hash = ? LIMIT 1`, contentHash).
+		Scan(&existing).Error; err != nil {
+		return "", "", fmt.Errorf("failed to look up stored file: %w", err)
+	}
+	if existing != "" {
+		return existing, contentHash, nil
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	if err := s.storage.UploadFile(key, file, fileType, header.Size); err != nil {
+		return "", "", fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return key, contentHash, nil
+}
+
+// fileUsers counts the resources, versions and attachments using a stored
+// file, after waiting for any upload that is sharing it to be committed
+func fileUsers(key, contentHash string) (int64, error) {
+	var users int64
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockContent(tx, contentHash); err != nil {
+			return err
+		}
+		if err := tx.Raw(`SELECT COUNT(*) FROM (`+liveFilesSQL+`) f WHERE s3_key = ?`, key).
+			Scan(&users).Error; err != nil {
+			return fmt.Errorf("failed to check stored file: %w", err)
+		}
+		return nil
+	})
+	return users, err
+}
+
+// releaseFile deletes a stored file unless a resource, version or attachment
+// still uses it. Once nothing uses the file no upload can start sharing it, so
+// it is safe to delete after the check. Failures are logged; the file is then
+// left behind.
+func (s *ResourceService) releaseFile(key, contentHash string) {
+	users, err := fileUsers(key, contentHash)
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+		return
+	}
+	if users > 0 {
+		return
+	}
+
+	if err := s.storage.DeleteFile(key); err != nil {
+		fmt.Printf("Warning: failed to delete file from S3: %v\n", err)
+	}
+}
+
+// mergeCollectionItems moves a merged resource's place in collections to the
+// resource it was merged into. Collections that hold both keep the target's item.
+func mergeCollectionItems(tx *gorm.DB, sourceID, targetID uuid.UUID) error {
+	var collectionIDs []uuid.UUID
+	if err := tx.Model(&models.CollectionItem{}).
+		Where("resource_id = ? AND collection_id IN (?)", sourceID,
+			tx.Model(&models.CollectionItem{}).Select("collection_id").Where("resource_id = ?", targetID)).
+		Pluck("collection_id", &collectionIDs).Error; err != nil {
+		return fmt.Errorf("failed to merge collection items: %w", err)
+	}
+
+	if len(collectionIDs) > 0 {
+		if err := tx.Where("resource_id = ? AND collection_id IN ?", sourceID, collectionIDs).
+			Delete(&models.CollectionItem{}).Error; err != nil {
+			return fmt.Errorf("failed to merge collection items: %w", err)
+		}
+		if err := tx.Model(&models.Collection{}).
+			Where("id IN ?", collectionIDs).
+			Update("item_count", gorm.Expr("item_count - 1")).Error; err != nil {
+			return fmt.Errorf("failed to update collections: %w", err)
+		}
+	}
+
+	if err := tx.Model(&models.CollectionItem{}).
+		Where("resource_id = ?", sourceID).
+		Update("resource_id", targetID).Error; err != nil {
+		return fmt.Errorf("failed to move collection items: %w", err)
+	}
+
+	return nil
+}
+
+// storedFile is a stored file and the SHA-256 of its content
+type storedFile struct {
+	Key         string `gorm:"column:s3_key"`
+	ContentHash string `gorm:"column:content_hash"`
+}
+
+// resourceFiles lists the stored files of a resource, its versions and its attachments
+func resourceFiles(resource *models.Resource) ([]storedFile, error) {
+	var files []storedFile
+	if err := database.DB.Raw(`
+		SELECT ? AS s3_key, ? AS content_hash
+		UNION
+		SELECT s3_key, content_hash FROM resource_versions WHERE resource_id = ?
+		UNION
+		SELECT s3_key, content_hash FROM resource_files WHERE resource_id = ?`, resource.S3Key, resource.ContentHash, resource.ID, resource.ID).
+		Scan(&files).Error; err != nil {
+		return nil, fmt.Errorf("failed to list resource files: %w", err)
+	}
+	return files, nil
+}
+
+// FindDuplicates lists resources the user can see whose file has the given
+// SHA-256, most downloaded first, leaving out excludeID
+func (s *ResourceService) FindDuplicates(contentHash string, userID *uuid.UUID, excludeID *uuid.UUID) ([]models.Resource, error) {
+	if contentHash == "" {
+		return []models.Resource{}, nil
+	}
+
+	query := database.DB.
+		Preload("User").
+		Preload("Course").
+		Scopes(visibleResources(userID)).
+		Where("content_hash = ? AND is_approved = ?", contentHash, true)
+	if excludeID != nil {
+		query = query.Where("id <> ?", *excludeID)
+	}
+
+	var resources []models.Resource
+	if err := query.
+		Order("download_count DESC, created_at").
+		Limit(duplicateLimit).
+		Find(&resources).Error; err != nil {
+		return nil, fmt.Errorf("failed to find duplicates: %w", err)
+	}
+
+	return resources, nil
+}
+
+// DuplicateGroup is a set of resources with identical files
+type DuplicateGroup struct {
+	ContentHash string            `json:"content_hash"`
+	Count       int64             `json:"count"`
+	Resources   []models.Resource `json:"resources"`
+}
+
+// ListDuplicateGroups lists the files uploaded as more than one resource, those
+// with the most copies first, for moderators to merge
+func (s *ResourceService) ListDuplicateGroups(page, pageSize int) ([]DuplicateGroup, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 50 {
+		pageSize = 20
+	}
+
+	duplicated := func() *gorm.DB {
+		return database.DB.Model(&models.Resource{}).
+			Select("content_hash, COUNT(*) AS count").
+			Where("content_hash <> ''").
+			Group("content_hash").
+			Having("COUNT(*) > 1")
+	}
+
+	var total int64
+	if err := database.DB.Table("(?) d", duplicated()).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count duplicates: %w", err)
+	}
+
+	var groups []DuplicateGroup
+	if err := duplicated().
+		Order("count DESC, content_hash").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Scan(&groups).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list duplicates: %w", err)
+	}
+
+	for i := range groups {
+		if err := database.DB.
+			Preload("User").
+			Where("content_hash = ?", groups[i].ContentHash).
+			Order("created_at").
+			Find(&groups[i].Resources).Error; err != nil {
+			return nil, 0, fmt.Errorf("failed to list duplicates: %w", err)
+		}
+	}
+
+	return groups, total, nil
+}
+
+// MergeResourceRequest represents a request to merge a duplicate resource into another
+type MergeResourceRequest struct {
+	TargetResourceID uuid.UUID `json:"target_resource_id" binding:"required"`
+}
+
+// MergeResources moves the comments, ratings, bookmarks, reports, tags,
+// collection items, activities and counters of a duplicate resource to the one it
+// duplicates and deletes the duplicate. Where a user rated or bookmarked both, or
+// a collection holds both, the target's rating, bookmark or item is kept.
+func (s *ResourceService) MergeResources(sourceID, targetID uuid.UUID) (*models.Resource, error) {
+	if sourceID == targetID {
+		return nil, ErrCannotMergeResourceSelf
+	}
+
+	var source, target models.Resource
+	if err := database.DB.Where("id = ?", sourceID).First(&source).Error; err != nil {
+		return nil, ErrResourceNotFound
+	}
+	if err := database.DB.Where("id = ?", targetID).First(&target).Error; err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	files, err := resourceFiles(&source)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// One rating, bookmark and download record per user and resource
+		for _, table := range []struct {
+			model interface{}
+			name  string
+		}{
+			{&models.Rating{}, "ratings"},
+			{&models.Bookmark{}, "bookmarks"},
+			{&models.ResourceDownload{}, "downloads"},
+		} {
+			if err := tx.Where("resource_id = ? AND user_id IN (?)", sourceID,
+				tx.Model(table.model).Select("user_id").Where("resource_id = ?", targetID)).
+				Delete(table.model).Error; err != nil {
+				return fmt.Errorf("failed to merge %s: %w", table.name, err)
+			}
+			if err := tx.Model(table.model).
+				Where("resource_id = ?", sourceID).
+				Update("resource_id", targetID).Error; err != nil {
+				return fmt.Errorf("failed to move %s: %w", table.name, err)
+			}
+		}
+
+		// Replies move along with their parent comment
+		if err := tx.Model(&models.Comment{}).
+			Where("resource_id = ?", sourceID).
+			Update("resource_id", targetID).Error; err != nil {
+			return fmt.Errorf("failed to move comments: %w", err)
+		}
+
+		if err := tx.Model(&models.Report{}).
+			Where("resource_id = ?", sourceID).
+			Update("resource_id", targetID).Error; err != nil {
+			return fmt.Errorf("failed to move reports: %w", err)
+		}
+
+		if err := tx.Model(&models.EngagementEvent{}).
+			Where("entity_type = ? AND entity_id = ?", "resource", sourceID).
+			Update("entity_id", targetID).Error; err != nil {
+			return fmt.Errorf("failed to move engagement: %w", err)
+		}
+
+		if err := tx.Model(&models.Activity{}).
+			Where("object_type = ? AND object_id = ?", "resource", sourceID).
+			Update("object_id", targetID).Error; err != nil {
+			return fmt.Errorf("failed to move activities: %w", err)
+		}
+
+		// The target keeps the tags of both
+		if err := tx.Where("resource_id = ? AND tag_id IN (?)", sourceID,
+			tx.Model(&models.ResourceTag{}).Select("tag_id").Where("resource_id = ?", targetID)).
+			Delete(&models.ResourceTag{}).Error; err != nil {
+			return fmt.Errorf("failed to merge tags: %w", err)
+		}
+		if err := tx.Model(&models.ResourceTag{}).
+			Where("resource_id = ?", sourceID).
+			Update("resource_id", targetID).Error; err != nil {
+			return fmt.Errorf("failed to move tags: %w", err)
+		}
+
+		if err := mergeCollectionItems(tx, sourceID, targetID); err != nil {
+			return err
+		}
+
+		// Similarities are recomputed for the target with its merged interactions
+		if err := tx.Where("resource_id = ? OR similar_resource_id = ?", sourceID, sourceID).
+			Delete(&models.ResourceSimilarity{}).Error; err != nil {
+			return fmt.Errorf("failed to delete similarities: %w", err)
+		}
+
+		if err := tx.Model(&target).UpdateColumns(map[string]interface{}{
+			"view_count":     gorm.Expr("view_count + ?", source.ViewCount),
+			"download_count": gorm.Expr("download_count + ?", source.DownloadCount),
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update merged resource: %w", err)
+		}
+
+		// The duplicate is not kept for recovery, so its files go straight away
+		if err := tx.Model(&source).Update("files_purged_at", time.Now()).Error; err != nil {
+			return fmt.Errorf("failed to delete merged resource: %w", err)
+		}
+		if err := tx.Delete(&source).Error; err != nil {
+			return fmt.Errorf("failed to delete merged resource: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Files identical to the target's are shared with it and stay
+	for _, file := range files {
+		s.releaseFile(file.Key, file.ContentHash)
+	}
+
+	return s.GetResourceByID(targetID)
+}
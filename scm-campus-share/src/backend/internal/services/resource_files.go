@@ -0,0 +1,296 @@
+package services
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/storage"
+)
+
+// maxResourceFiles caps how many files can be attached to one resource
+const maxResourceFiles = 20
+
+// validateAttachments checks files about to be attached to a resource that
+// already has existing attachments
+func validateAttachments(files []*multipart.FileHeader, existing int64, maxFileSize int64, allowedTypes []string) error {
+	if existing+int64(len(files)) > maxResourceFiles {
+		return ErrTooManyFiles
+	}
+
+	for _, file := range files {
+		if _, err := validateUpload(file, maxFileSize, allowedTypes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// uploadAttachments stores validated files in S3 within the transaction that
+// records them and returns their records, numbered from position. If one fails,
+// those already stored are returned with the error, to be released once the
+// transaction has rolled back.
+func (s *ResourceService) uploadAttachments(tx *gorm.DB, userID, resourceID uuid.UUID, files []*multipart.FileHeader, position int) ([]models.ResourceFile, error) {
+	attachments := make([]models.ResourceFile, 0, len(files))
+	for i, header := range files {
+		attachment := models.ResourceFile{
+			ID:         uuid.New(),
+			ResourceID: resourceID,
+			FileName:   header.Filename,
+			FileSize:   header.Size,
+			FileType:   header.Header.Get("Content-Type"),
+			Position:   position + i,
+		}
+
+		key := storage.GenerateAttachmentKey(userID.String(), resourceID.String(), attachment.ID.String(), header.Filename)
+		s3Key, contentHash, err := s.storeUpload(tx, key, header, attachment.FileType)
+		if err != nil {
+			return attachments, err
+		}
+		attachment.S3Key = s3Key
+		attachment.ContentHash = contentHash
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, nil
+}
+
+// releaseAttachmentFiles removes the stored files of attachments that nothing else uses
+func (s *ResourceService) releaseAttachmentFiles(attachments []models.ResourceFile) {
+	for _, attachment := range attachments {
+		s.releaseFile(attachment.S3Key, attachment.ContentHash)
+	}
+}
+
+// AddFiles attaches more files to a resource
+func (s *ResourceService) AddFiles(resourceID, userID uuid.UUID, files []*multipart.FileHeader, maxFileSize int64, allowedTypes []string) ([]models.ResourceFile, error) {
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	// Check ownership
+	if resource.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	if len(files) == 0 {
+		return nil, errors.New("file is required")
+	}
+
+	var existing int64
+	var position int
+	if err := database.DB.Model(&models.ResourceFile{}).
+		Where("resource_id = ?", resourceID).
+		Count(&existing).Error; err != nil {
+		return nil, fmt.Errorf("failed to count files: %w", err)
+	}
+	if err := database.DB.Model(&models.ResourceFile{}).
+		Where("resource_id = ?", resourceID).
+		Select("COALESCE(MAX(position) + 1, 0)").
+		Scan(&position).Error; err != nil {
+		return nil, fmt.Errorf("failed to get file position: %w", err)
+	}
+
+	if err := validateAttachments(files, existing, maxFileSize, allowedTypes); err != nil {
+		return nil, err
+	}
+
+	var size int64
+	for _, file := range files {
+		size += file.Size
+	}
+
+	var attachments []models.ResourceFile
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := s.quotaService.CheckUpload(tx, userID, size, int64(len(files))); err != nil {
+			return err
+		}
+
+		var err error
+		attachments, err = s.uploadAttachments(tx, userID, resourceID, files, position)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&attachments).Error; err != nil {
+			return fmt.Errorf("failed to attach files: %w", err)
+		}
+		return markScanPending(tx, resourceID)
+	})
+	if err != nil {
+		// Clean up uploaded files if database insert fails
+		s.releaseAttachmentFiles(attachments)
+		return nil, err
+	}
+
+	queueScan(resourceID)
+
+	return attachments, nil
+}
+
+// RemoveFile detaches a file from a resource and deletes it
+func (s *ResourceService) RemoveFile(resourceID, fileID, userID uuid.UUID) error {
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		return ErrResourceNotFound
+	}
+
+	// Check ownership
+	if resource.UserID != userID {
+		return ErrUnauthorized
+	}
+
+	attachment, err := getAttachment(resourceID, fileID)
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Delete(attachment).Error; err != nil {
+		return fmt.Errorf("failed to remove file: %w", err)
+	}
+
+	s.releaseAttachmentFiles([]models.ResourceFile{*attachment})
+
+	return nil
+}
+
+// DownloadFile counts a download of the resource and returns a presigned URL
+// for one of its attached files
+func (s *ResourceService) DownloadFile(resourceID, fileID, userID uuid.UUID, viewer Viewer) (string, error) {
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		return "", ErrResourceNotFound
+	}
+
+	attachment, err := getAttachment(resourceID, fileID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.download(&resource, attachment.S3Key, userID, viewer)
+}
+
+func getAttachment(resourceID, fileID uuid.UUID) (*models.ResourceFile, error) {
+	var attachment models.ResourceFile
+	if err := database.DB.Where("id = ? AND resource_id = ?", fileID, resourceID).First(&attachment).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFileNotFound
+		}
+		return nil, fmt.Errorf("failed to get file: %w", err)
+	}
+	return &attachment, nil
+}
+
+// Bundle is every file of a resource, to be written as one ZIP archive
+type Bundle struct {
+	Name  string // File name of the archive
+	files []bundleFile
+}
+
+type bundleFile struct {
+	name string
+	key  string
+}
+
+// GetBundle counts a download of the resource and lists its main file and
+// attachments for WriteBundle
+func (s *ResourceService) GetBundle(resourceID, userID uuid.UUID, viewer Viewer) (*Bundle, error) {
+	var resource models.Resource
+	if err := database.DB.
+		Preload("Files", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, created_at")
+		}).
+		Where("id = ?", resourceID).
+		First(&resource).Error; err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	bundle := &Bundle{Name: bundleName(resource.Title)}
+	names := make(map[string]bool)
+	bundle.files = append(bundle.files, bundleFile{name: uniqueName(resource.FileName, names), key: resource.S3Key})
+	for _, attachment := range resource.Files {
+		bundle.files = append(bundle.files, bundleFile{name: uniqueName(attachment.FileName, names), key: attachment.S3Key})
+	}
+
+	if _, err := s.download(&resource, resource.S3Key, userID, viewer); err != nil {
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+// WriteBundle streams a bundle to w as a ZIP archive. Each file is copied from
+// S3 into the archive as it is read, so no file is held in memory whole.
+func (s *ResourceService) WriteBundle(bundle *Bundle, w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	for _, file := range bundle.files {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate})
+		if err != nil {
+			return fmt.Errorf("failed to add %s to archive: %w", file.name, err)
+		}
+
+		body, err := s.storage.GetFile(file.key)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, body)
+		body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write %s to archive: %w", file.name, err)
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return nil
+}
+
+// uniqueName returns a file name not yet in names, numbering repeats as
+// "notes (2).pdf", and adds it to names
+func uniqueName(name string, names map[string]bool) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	ext := path.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = fmt.Sprintf("%s (%d)%s", base, i, ext)
+	}
+	names[unique] = true
+
+	return unique
+}
+
+// bundleName makes a safe archive file name from a resource title
+func bundleName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			return r
+		case unicode.IsSpace(r):
+			return '-'
+		default:
+			return -1
+		}
+	}, title)
+
+	if name == "" {
+		name = "resource"
+	}
+	return name + ".zip"
+}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"github.com/campus-share/backend/internal/config"
 	"github.com/campus-share/backend/internal/database"
 	"github.com/campus-share/backend/internal/models"
 	"github.com/campus-share/backend/internal/storage"
@@ -19,77 +21,67 @@ var (
 	ErrUnauthorized     = errors.New("unauthorized")
 	ErrFileTooLarge     = errors.New("file too large")
 	ErrInvalidFileType  = errors.New("invalid file type")
+	ErrVersionNotFound  = errors.New("version not found")
+	ErrCatalogMismatch  = errors.New("course does not belong to the department and university")
+	ErrFileNotFound     = errors.New("file not found")
+	ErrTooManyFiles     = errors.New("too many files")
 )
 
 // ResourceService handles resource-related operations
 type ResourceService struct {
-	storage *storage.S3Storage
+	storage             *storage.S3Storage
+	activityService     *ActivityService
+	engagementService   *EngagementService
+	notificationService *NotificationService
+	quotaService        *QuotaService
+	retention           time.Duration // How long deleted resources can be restored
 }
 
 // NewResourceService creates a new resource service
-func NewResourceService(s3Storage *storage.S3Storage) *ResourceService {
+func NewResourceService(s3Storage *storage.S3Storage, quotaCfg *config.QuotaConfig, storageCfg *config.StorageConfig) *ResourceService {
 	return &ResourceService{
-		storage: s3Storage,
+		storage:             s3Storage,
+		activityService:     NewActivityService(),
+		engagementService:   NewEngagementService(),
+		notificationService: NewNotificationService(),
+		quotaService:        NewQuotaService(quotaCfg),
+		retention:           storageCfg.Retention,
 	}
 }
 
 // CreateResourceRequest represents a request to create a resource
 type CreateResourceRequest struct {
-	Title        string               `json:"title" binding:"required"`
-	Description  string               `json:"description"`
-	Type         models.ResourceType  `json:"type" binding:"required"`
-	UniversityID *uuid.UUID           `json:"university_id,omitempty"`
-	DepartmentID *uuid.UUID           `json:"department_id,omitempty"`
-	CourseID     *uuid.UUID           `json:"course_id,omitempty"`
-	SharingLevel models.SharingLevel  `json:"sharing_level"`
-	Tags         []string             `json:"tags,omitempty"`
-	File         multipart.FileHeader `json:"-"`
+	Title        string                  `json:"title" binding:"required"`
+	Description  string                  `json:"description"`
+	Type         models.ResourceType     `json:"type" binding:"required"`
+	UniversityID *uuid.UUID              `json:"university_id,omitempty"`
+	DepartmentID *uuid.UUID              `json:"department_id,omitempty"`
+	CourseID     *uuid.UUID              `json:"course_id,omitempty"`
+	SharingLevel models.SharingLevel     `json:"sharing_level"`
+	Tags         []string                `json:"tags,omitempty"`
+	File         multipart.FileHeader    `json:"-"`
+	Attachments  []*multipart.FileHeader `json:"-"` // Files attached besides the main one
 }
 
 // CreateResource creates a new resource
 func (s *ResourceService) CreateResource(userID uuid.UUID, req CreateResourceRequest, maxFileSize int64, allowedTypes []string) (*models.Resource, error) {
 	// Validate file
-	if req.File.Size == 0 {
-		return nil, errors.New("file is required")
+	fileType, err := validateUpload(&req.File, maxFileSize, allowedTypes)
+	if err != nil {
+		return nil, err
 	}
-
-	if req.File.Size > maxFileSize*1024*1024 {
-		return nil, ErrFileTooLarge
+	if err := validateAttachments(req.Attachments, 0, maxFileSize, allowedTypes); err != nil {
+		return nil, err
 	}
 
-	// Validate file type
-	fileType := req.File.Header.Get("Content-Type")
-	allowed := false
-	for _, allowedType := range allowedTypes {
-		if fileType == fmt.Sprintf("application/%s", allowedType) ||
-			fileType == fmt.Sprintf("image/%s", allowedType) ||
-			fileType == fmt.Sprintf("video/%s", allowedType) {
-			allowed = true
-			break
-		}
-	}
-	if !allowed {
-		return nil, ErrInvalidFileType
+	size := req.File.Size
+	for _, attachment := range req.Attachments {
+		size += attachment.Size
 	}
 
 	// Generate resource ID
 	resourceID := uuid.New()
 
-	// Generate S3 key
-	s3Key := storage.GenerateKey(userID.String(), resourceID.String(), req.File.Filename)
-
-	// Open file
-	file, err := req.File.Open()
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
-
-	// Upload to S3
-	if err := s.storage.UploadFile(s3Key, file, fileType, req.File.Size); err != nil {
-		return nil, fmt.Errorf("failed to upload file: %w", err)
-	}
-
 	// Create resource record
 	resource := models.Resource{
 		ID:           resourceID,
@@ -100,24 +92,68 @@ func (s *ResourceService) CreateResource(userID uuid.UUID, req CreateResourceReq
 		FileName:     req.File.Filename,
 		FileSize:     req.File.Size,
 		FileType:     fileType,
-		S3Key:        s3Key,
 		UniversityID: req.UniversityID,
 		DepartmentID: req.DepartmentID,
 		CourseID:     req.CourseID,
 		SharingLevel: req.SharingLevel,
 		IsApproved:   true, // Auto-approve for now, can be changed to require moderation
+		ScanStatus:   models.ScanStatusPending,
 	}
 
 	if resource.SharingLevel == "" {
 		resource.SharingLevel = models.SharingLevelPublic
 	}
 
-	if err := database.DB.Create(&resource).Error; err != nil {
-		// Clean up uploaded file if database insert fails
-		_ = s.storage.DeleteFile(s3Key)
-		return nil, fmt.Errorf("failed to create resource: %w", err)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Check the uploader has room for the files
+		if err := s.quotaService.CheckUpload(tx, userID, size, int64(1+len(req.Attachments))); err != nil {
+			return err
+		}
+
+		// Upload to S3, sharing the stored file if the same content was uploaded before
+		var err error
+		resource.S3Key, resource.ContentHash, err = s.storeUpload(tx, storage.GenerateKey(userID.String(), resourceID.String(), req.File.Filename), &req.File, fileType)
+		if err != nil {
+			return err
+		}
+
+		resource.Files, err = s.uploadAttachments(tx, userID, resourceID, req.Attachments, 0)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(&resource).Error; err != nil {
+			return fmt.Errorf("failed to create resource: %w", err)
+		}
+
+		// The original upload is the first version
+		version := models.ResourceVersion{
+			ResourceID:  resourceID,
+			Number:      1,
+			UserID:      userID,
+			FileName:    resource.FileName,
+			FileSize:    resource.FileSize,
+			FileType:    resource.FileType,
+			S3Key:       resource.S3Key,
+			ContentHash: resource.ContentHash,
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			return fmt.Errorf("failed to record first version: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		// Clean up uploaded files if database insert fails
+		if resource.S3Key != "" {
+			s.releaseFile(resource.S3Key, resource.ContentHash)
+		}
+		s.releaseAttachmentFiles(resource.Files)
+		return nil, err
 	}
 
+	// Downloads are blocked until the files pass the malware scan
+	queueScan(resourceID)
+
 	// Add tags
 	if len(req.Tags) > 0 {
 		if err := s.addTagsToResource(resourceID, req.Tags); err != nil {
@@ -126,10 +162,36 @@ func (s *ResourceService) CreateResource(userID uuid.UUID, req CreateResourceReq
 		}
 	}
 
+	if err := s.activityService.Record(userID, models.ActivityTypeResourceUploaded, "resource", resourceID, resource.CourseID); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
 	// Load relations
 	return s.GetResourceByID(resourceID)
 }
 
+// validateUpload checks an uploaded file's size and type and returns its MIME type
+func validateUpload(file *multipart.FileHeader, maxFileSize int64, allowedTypes []string) (string, error) {
+	if file.Size == 0 {
+		return "", errors.New("file is required")
+	}
+
+	if file.Size > maxFileSize*1024*1024 {
+		return "", ErrFileTooLarge
+	}
+
+	fileType := file.Header.Get("Content-Type")
+	for _, allowedType := range allowedTypes {
+		if fileType == fmt.Sprintf("application/%s", allowedType) ||
+			fileType == fmt.Sprintf("image/%s", allowedType) ||
+			fileType == fmt.Sprintf("video/%s", allowedType) {
+			return fileType, nil
+		}
+	}
+
+	return "", ErrInvalidFileType
+}
+
 // GetResourceByID retrieves a resource by ID
 func (s *ResourceService) GetResourceByID(resourceID uuid.UUID) (*models.Resource, error) {
 	var resource models.Resource
@@ -139,6 +201,9 @@ func (s *ResourceService) GetResourceByID(resourceID uuid.UUID) (*models.Resourc
 		Preload("Department").
 		Preload("Course").
 		Preload("Tags.Tag").
+		Preload("Files", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, created_at")
+		}).
 		Where("id = ?", resourceID).
 		First(&resource).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -147,18 +212,37 @@ func (s *ResourceService) GetResourceByID(resourceID uuid.UUID) (*models.Resourc
 		return nil, fmt.Errorf("failed to get resource: %w", err)
 	}
 
-	// Increment view count
-	database.DB.Model(&resource).UpdateColumn("view_count", gorm.Expr("view_count + 1"))
-
-	// Generate presigned URL
-	url, err := s.storage.GetPresignedURL(resource.S3Key, 15*time.Minute)
-	if err == nil {
-		resource.S3URL = url
+	// Generate presigned URL once the file passed the malware scan
+	if checkScan(&resource) == nil {
+		url, err := s.storage.GetPresignedURL(resource.S3Key, 15*time.Minute)
+		if err == nil {
+			resource.S3URL = url
+		}
 	}
 
 	return &resource, nil
 }
 
+// ViewResource retrieves a resource by ID and counts the view
+func (s *ResourceService) ViewResource(resourceID uuid.UUID, viewer Viewer) (*models.Resource, error) {
+	resource, err := s.GetResourceByID(resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.engagementService.RecordView("resource", resource.ID, resource.UserID, models.EngagementTypeView, viewer); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	bookmarked, err := bookmarkedResources(viewer.UserID, []uuid.UUID{resource.ID})
+	if err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+	resource.IsBookmarked = bookmarked[resource.ID]
+
+	return resource, nil
+}
+
 // ListResourcesRequest represents a request to list resources
 type ListResourcesRequest struct {
 	Page         int        `form:"page"`
@@ -169,7 +253,7 @@ type ListResourcesRequest struct {
 	DepartmentID *uuid.UUID `form:"department_id"`
 	CourseID     *uuid.UUID `form:"course_id"`
 	Tag          string     `form:"tag"`
-	SortBy       string     `form:"sort_by"` // "newest", "popular", "rating"
+	SortBy       string     `form:"sort_by"` // "newest", "popular", "trending", "rating"
 }
 
 // ListResources lists resources with filtering and pagination
@@ -210,22 +294,7 @@ func (s *ResourceService) ListResources(req ListResourcesRequest, userID *uuid.U
 	}
 
 	// Apply sharing level filter
-	if userID != nil {
-		// Get user's university
-		var user models.User
-		if err := database.DB.Where("id = ?", userID).First(&user).Error; err == nil {
-			if user.UniversityID != nil {
-				query = query.Where("sharing_level = ? OR (sharing_level = ? AND university_id = ?)",
-					models.SharingLevelPublic, models.SharingLevelUniversity, user.UniversityID)
-			} else {
-				query = query.Where("sharing_level = ?", models.SharingLevelPublic)
-			}
-		} else {
-			query = query.Where("sharing_level = ?", models.SharingLevelPublic)
-		}
-	} else {
-		query = query.Where("sharing_level = ?", models.SharingLevelPublic)
-	}
+	query = query.Scopes(visibleResources(userID))
 
 	// Get total count
 	var total int64
@@ -237,6 +306,8 @@ func (s *ResourceService) ListResources(req ListResourcesRequest, userID *uuid.U
 	switch req.SortBy {
 	case "popular":
 		query = query.Order("download_count DESC, view_count DESC")
+	case "trending":
+		query = query.Scopes(OrderByTrending("resource", "resources"))
 	case "rating":
 		// This would require a join with ratings table and average calculation
 		query = query.Order("created_at DESC")
@@ -261,26 +332,79 @@ func (s *ResourceService) ListResources(req ListResourcesRequest, userID *uuid.U
 
 	// Generate presigned URLs
 	for i := range resources {
+		if checkScan(&resources[i]) != nil {
+			continue
+		}
 		url, err := s.storage.GetPresignedURL(resources[i].S3Key, 15*time.Minute)
 		if err == nil {
 			resources[i].S3URL = url
 		}
 	}
 
+	markBookmarked(resources, userID)
+
 	return resources, total, nil
 }
 
-// UpdateResourceRequest represents a request to update a resource
+// visibleResources limits a resource query to the sharing levels a user may see.
+// Anonymous users only see public resources.
+func visibleResources(userID *uuid.UUID) func(*gorm.DB) *gorm.DB {
+	var universityID *uuid.UUID
+	if userID != nil {
+		// Get user's university
+		var user models.User
+		if err := database.DB.Where("id = ?", userID).First(&user).Error; err == nil {
+			universityID = user.UniversityID
+		}
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if universityID != nil {
+			return db.Where("sharing_level = ? OR (sharing_level = ? AND university_id = ?)",
+				models.SharingLevelPublic, models.SharingLevelUniversity, universityID)
+		}
+		return db.Where("sharing_level = ?", models.SharingLevelPublic)
+	}
+}
+
+// checkVisible returns ErrResourceNotFound unless the user may see the resource.
+// Owners and moderators see every resource, others only approved ones shared
+// with them.
+func checkVisible(resource *models.Resource, userID *uuid.UUID, isModerator bool) error {
+	if isModerator || (userID != nil && resource.UserID == *userID) {
+		return nil
+	}
+
+	var visible int64
+	if err := database.DB.Model(&models.Resource{}).
+		Where("id = ? AND is_approved = ?", resource.ID, true).
+		Scopes(visibleResources(userID)).
+		Count(&visible).Error; err != nil {
+		return fmt.Errorf("failed to get resource: %w", err)
+	}
+	if visible == 0 {
+		return ErrResourceNotFound
+	}
+	return nil
+}
+
+// UpdateResourceRequest represents a request to update a resource. Giving a course,
+// department or university moves the resource; the most specific one wins and
+// implies the others, which must match it when also given.
 type UpdateResourceRequest struct {
-	Title        string               `json:"title,omitempty"`
-	Description  string               `json:"description,omitempty"`
-	Type         *models.ResourceType `json:"type,omitempty"`
-	SharingLevel *models.SharingLevel `json:"sharing_level,omitempty"`
-	Tags         []string             `json:"tags,omitempty"`
+	Title        string                `json:"title,omitempty"`
+	Description  string                `json:"description,omitempty"`
+	Type         *models.ResourceType  `json:"type,omitempty"`
+	SharingLevel *models.SharingLevel  `json:"sharing_level,omitempty"`
+	Tags         []string              `json:"tags,omitempty"`
+	UniversityID *uuid.UUID            `json:"university_id,omitempty"`
+	DepartmentID *uuid.UUID            `json:"department_id,omitempty"`
+	CourseID     *uuid.UUID            `json:"course_id,omitempty"`
+	File         *multipart.FileHeader `json:"-"` // Uploaded as a new current version
 }
 
 // UpdateResource updates a resource
-func (s *ResourceService) UpdateResource(resourceID, userID uuid.UUID, req UpdateResourceRequest) (*models.Resource, error) {
+func (s *ResourceService) UpdateResource(resourceID, userID uuid.UUID, req UpdateResourceRequest, maxFileSize int64, allowedTypes []string) (*models.Resource, error) {
 	var resource models.Resource
 	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
 		return nil, ErrResourceNotFound
@@ -291,22 +415,47 @@ func (s *ResourceService) UpdateResource(resourceID, userID uuid.UUID, req Updat
 		return nil, ErrUnauthorized
 	}
 
-	// Update fields
+	// Only the columns this request changes are written, so counters and the
+	// approval and scan state updated meanwhile are kept
+	updates := map[string]interface{}{}
 	if req.Title != "" {
-		resource.Title = req.Title
+		updates["title"] = req.Title
 	}
 	if req.Description != "" {
-		resource.Description = req.Description
+		updates["description"] = req.Description
 	}
 	if req.Type != nil {
-		resource.Type = *req.Type
+		updates["type"] = *req.Type
 	}
 	if req.SharingLevel != nil {
-		resource.SharingLevel = *req.SharingLevel
+		updates["sharing_level"] = *req.SharingLevel
 	}
 
-	if err := database.DB.Save(&resource).Error; err != nil {
-		return nil, fmt.Errorf("failed to update resource: %w", err)
+	if req.UniversityID != nil || req.DepartmentID != nil || req.CourseID != nil {
+		universityID, departmentID, courseID, err := resolveCatalog(req.UniversityID, req.DepartmentID, req.CourseID)
+		if err != nil {
+			return nil, err
+		}
+		updates["university_id"] = universityID
+		updates["department_id"] = departmentID
+		updates["course_id"] = courseID
+	}
+
+	// A replacement file becomes a new current version, so the old file stays
+	// in the version history
+	if req.File != nil {
+		if _, err := s.UploadVersion(resourceID, userID, UploadVersionRequest{
+			MakeCurrent: true,
+			File:        *req.File,
+		}, maxFileSize, allowedTypes); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(updates) > 0 {
+		if err := database.DB.Model(&resource).Updates(updates).Error; err != nil {
+			return nil, fmt.Errorf("failed to update resource: %w", err)
+		}
 	}
 
 	// Update tags if provided
@@ -322,6 +471,39 @@ func (s *ResourceService) UpdateResource(resourceID, userID uuid.UUID, req Updat
 	return s.GetResourceByID(resourceID)
 }
 
+// resolveCatalog returns the university, department and course a resource is
+// placed in. The most specific of those given is looked up and implies the
+// others; any also given must match it.
+func resolveCatalog(universityID, departmentID, courseID *uuid.UUID) (*uuid.UUID, *uuid.UUID, *uuid.UUID, error) {
+	switch {
+	case courseID != nil:
+		var course models.Course
+		if err := database.DB.Where("id = ?", courseID).First(&course).Error; err != nil {
+			return nil, nil, nil, ErrCourseNotFound
+		}
+		if (departmentID != nil && *departmentID != course.DepartmentID) ||
+			(universityID != nil && *universityID != course.UniversityID) {
+			return nil, nil, nil, ErrCatalogMismatch
+		}
+		return &course.UniversityID, &course.DepartmentID, &course.ID, nil
+	case departmentID != nil:
+		var department models.Department
+		if err := database.DB.Where("id = ?", departmentID).First(&department).Error; err != nil {
+			return nil, nil, nil, ErrDepartmentNotFound
+		}
+		if universityID != nil && *universityID != department.UniversityID {
+			return nil, nil, nil, ErrCatalogMismatch
+		}
+		return &department.UniversityID, &department.ID, nil, nil
+	default:
+		var university models.University
+		if err := database.DB.Where("id = ?", universityID).First(&university).Error; err != nil {
+			return nil, nil, nil, ErrUniversityNotFound
+		}
+		return &university.ID, nil, nil, nil
+	}
+}
+
 // DeleteResource deletes a resource
 func (s *ResourceService) DeleteResource(resourceID, userID uuid.UUID, isAdmin bool) error {
 	var resource models.Resource
@@ -334,13 +516,8 @@ func (s *ResourceService) DeleteResource(resourceID, userID uuid.UUID, isAdmin b
 		return ErrUnauthorized
 	}
 
-	// Delete from S3
-	if err := s.storage.DeleteFile(resource.S3Key); err != nil {
-		// Log error but continue with database deletion
-		fmt.Printf("Warning: failed to delete file from S3: %v\n", err)
-	}
-
-	// Delete from database
+	// Delete from database. The resource stays in its owner's trash, and its
+	// files in S3, until the retention period is over.
 	if err := database.DB.Delete(&resource).Error; err != nil {
 		return fmt.Errorf("failed to delete resource: %w", err)
 	}
@@ -348,18 +525,42 @@ func (s *ResourceService) DeleteResource(resourceID, userID uuid.UUID, isAdmin b
 	return nil
 }
 
-// DownloadResource increments download count and returns presigned URL
-func (s *ResourceService) DownloadResource(resourceID uuid.UUID) (string, error) {
+// DownloadResource increments download count, remembers that the user downloaded
+// the resource and returns presigned URL
+func (s *ResourceService) DownloadResource(resourceID, userID uuid.UUID, viewer Viewer) (string, error) {
 	var resource models.Resource
 	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
 		return "", ErrResourceNotFound
 	}
 
-	// Increment download count
-	database.DB.Model(&resource).UpdateColumn("download_count", gorm.Expr("download_count + 1"))
+	return s.download(&resource, resource.S3Key, userID, viewer)
+}
+
+// download counts a download of the resource and returns a presigned URL for the file at key
+func (s *ResourceService) download(resource *models.Resource, key string, userID uuid.UUID, viewer Viewer) (string, error) {
+	if err := checkScan(resource); err != nil {
+		return "", err
+	}
+
+	resourceID := resource.ID
+
+	// Used for "people who downloaded this also downloaded" recommendations
+	download := models.ResourceDownload{
+		UserID:     userID,
+		ResourceID: resourceID,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&download).Error; err != nil {
+		fmt.Printf("Warning: failed to record download: %v\n", err)
+	}
+
+	// Counts towards download_count once per user and day
+	viewer.UserID = &userID
+	if err := s.engagementService.RecordView("resource", resourceID, resource.UserID, models.EngagementTypeDownload, viewer); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
 
 	// Generate presigned URL (valid for 1 hour)
-	url, err := s.storage.GetPresignedURL(resource.S3Key, 1*time.Hour)
+	url, err := s.storage.GetPresignedURL(key, 1*time.Hour)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate download URL: %w", err)
 	}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+)
+
+// TrashedResource is a deleted resource that can still be restored
+type TrashedResource struct {
+	models.Resource
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"` // When it and its files are gone for good
+}
+
+// inTrash limits a query to deleted resources that can still be restored
+func (s *ResourceService) inTrash(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().
+		Where("resources.deleted_at IS NOT NULL AND resources.files_purged_at IS NULL").
+		Where("resources.deleted_at >= ?", time.Now().Add(-s.retention))
+}
+
+// ListTrash lists a user's deleted resources that can still be restored, most
+// recently deleted first
+func (s *ResourceService) ListTrash(userID uuid.UUID, page, pageSize int) ([]TrashedResource, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	query := database.DB.Model(&models.Resource{}).
+		Scopes(s.inTrash).
+		Where("user_id = ?", userID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count trash: %w", err)
+	}
+
+	var resources []models.Resource
+	if err := query.
+		Preload("Course").
+		Preload("Tags.Tag").
+		Order("deleted_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&resources).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list trash: %w", err)
+	}
+
+	trashed := make([]TrashedResource, len(resources))
+	for i, resource := range resources {
+		trashed[i] = TrashedResource{
+			Resource:  resource,
+			DeletedAt: resource.DeletedAt.Time,
+			PurgeAt:   resource.DeletedAt.Time.Add(s.retention),
+		}
+	}
+
+	return trashed, total, nil
+}
+
+// getTrashed gets a deleted resource that can still be restored, checking the
+// user owns it unless they are an admin
+func (s *ResourceService) getTrashed(resourceID, userID uuid.UUID, isAdmin bool) (*models.Resource, error) {
+	var resource models.Resource
+	if err := database.DB.Scopes(s.inTrash).Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+
+	if resource.UserID != userID && !isAdmin {
+		return nil, ErrResourceNotFound
+	}
+
+	return &resource, nil
+}
+
+// RestoreResource takes a resource out of the trash. Its files, versions, tags,
+// comments and ratings were kept and come back with it.
+func (s *ResourceService) RestoreResource(resourceID, userID uuid.UUID, isAdmin bool) (*models.Resource, error) {
+	resource, err := s.getTrashed(resourceID, userID, isAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Files in the trash do not count against the quota, so the owner needs
+		// room for them again
+		if !isAdmin {
+			bytes, files, err := s.quotaService.ResourceUsage(resourceID)
+			if err != nil {
+				return err
+			}
+			if err := s.quotaService.CheckUpload(tx, resource.UserID, bytes, files); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Model(resource).Update("deleted_at", nil).Error; err != nil {
+			return fmt.Errorf("failed to restore resource: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetResourceByID(resourceID)
+}
+
+// PurgeResource deletes a resource in the trash for good, along with its files
+// unless identical uploads still use them
+func (s *ResourceService) PurgeResource(resourceID, userID uuid.UUID, isAdmin bool) error {
+	resource, err := s.getTrashed(resourceID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+
+	files, err := resourceFiles(resource)
+	if err != nil {
+		return err
+	}
+
+	if err := database.DB.Unscoped().Model(resource).Update("files_purged_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to purge resource: %w", err)
+	}
+
+	for _, file := range files {
+		s.releaseFile(file.Key, file.ContentHash)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"mime/multipart"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/storage"
+)
+
+// UploadVersionRequest represents a request to upload a new version of a resource
+type UploadVersionRequest struct {
+	ChangeNote  string
+	MakeCurrent bool // Point the resource at the new version straight away
+	File        multipart.FileHeader
+}
+
+// ListVersions lists the versions of a resource the user may see, newest first
+func (s *ResourceService) ListVersions(resourceID uuid.UUID, userID *uuid.UUID, isModerator bool) ([]models.ResourceVersion, error) {
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	if err := checkVisible(&resource, userID, isModerator); err != nil {
+		return nil, err
+	}
+
+	var versions []models.ResourceVersion
+	if err := database.DB.
+		Preload("User").
+		Where("resource_id = ?", resourceID).
+		Order("number DESC").
+		Find(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+
+	for i := range versions {
+		versions[i].IsCurrent = versions[i].Number == resource.CurrentVersion
+	}
+
+	return versions, nil
+}
+
+// UploadVersion stores a new revision of a resource's file. Comments, ratings and
+// bookmarks stay with the resource, and earlier versions remain downloadable.
+func (s *ResourceService) UploadVersion(resourceID, userID uuid.UUID, req UploadVersionRequest, maxFileSize int64, allowedTypes []string) (*models.ResourceVersion, error) {
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	// Check ownership
+	if resource.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	fileType, err := validateUpload(&req.File, maxFileSize, allowedTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	version := models.ResourceVersion{
+		ResourceID: resourceID,
+		UserID:     userID,
+		ChangeNote: req.ChangeNote,
+		FileName:   req.File.Filename,
+		FileSize:   req.File.Size,
+		FileType:   fileType,
+	}
+
+	key := storage.GenerateVersionKey(userID.String(), resourceID.String(), req.File.Filename)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Locking the resource numbers concurrent uploads one after the other
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", resourceID).First(&resource).Error; err != nil {
+			return ErrResourceNotFound
+		}
+
+		// Earlier versions are kept, so every version counts against the quota
+		if err := s.quotaService.CheckUpload(tx, userID, req.File.Size, 1); err != nil {
+			return err
+		}
+
+		var err error
+		version.S3Key, version.ContentHash, err = s.storeUpload(tx, key, &req.File, fileType)
+		if err != nil {
+			return err
+		}
+
+		var latest int
+		if err := tx.Model(&models.ResourceVersion{}).
+			Where("resource_id = ?", resourceID).
+			Select("COALESCE(MAX(number), 0)").
+			Scan(&latest).Error; err != nil {
+			return fmt.Errorf("failed to get latest version: %w", err)
+		}
+		version.Number = latest + 1
+
+		if err := tx.Create(&version).Error; err != nil {
+			return fmt.Errorf("failed to create version: %w", err)
+		}
+
+		// A version that is not made current does not change what the resource
+		// serves, so its current file stays downloadable while the new one is
+		// scanned. The new file's own scan is tracked until then.
+		if !req.MakeCurrent {
+			return markFileScanPending(tx, version.S3Key)
+		}
+		if err := markScanPending(tx, resourceID); err != nil {
+			return err
+		}
+		return setCurrentVersion(tx, &resource, &version)
+	})
+	if err != nil {
+		// Clean up uploaded file if database insert fails
+		if version.S3Key != "" {
+			s.releaseFile(version.S3Key, version.ContentHash)
+		}
+		return nil, err
+	}
+
+	queueScan(resourceID)
+
+	if req.MakeCurrent {
+		s.notifyBookmarkers(&resource, &version)
+	}
+
+	version.IsCurrent = req.MakeCurrent
+	return &version, nil
+}
+
+// SetCurrentVersion points a resource at one of its versions, which is then
+// what the resource shows and downloads
+func (s *ResourceService) SetCurrentVersion(resourceID, userID uuid.UUID, number int) (*models.Resource, error) {
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		return nil, ErrResourceNotFound
+	}
+
+	// Check ownership
+	if resource.UserID != userID {
+		return nil, ErrUnauthorized
+	}
+
+	version, err := getVersion(resourceID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	// A version uploaded without becoming current may not have passed the scan
+	pending := false
+	if err := checkFileScan(version.S3Key); err != nil {
+		if err != ErrScanPending && err != ErrFileQuarantined {
+			return nil, err
+		}
+		pending = true
+	}
+
+	previous := resource.CurrentVersion
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := setCurrentVersion(tx, &resource, version); err != nil {
+			return err
+		}
+		if pending {
+			return markScanPending(tx, resourceID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if pending {
+		queueScan(resourceID)
+	}
+
+	// Going back to an earlier version is not news to bookmarkers
+	if version.Number > previous {
+		s.notifyBookmarkers(&resource, version)
+	}
+
+	return s.GetResourceByID(resourceID)
+}
+
+// DownloadVersion counts a download of the resource and returns a presigned URL
+// for the file of one of its versions
+func (s *ResourceService) DownloadVersion(resourceID uuid.UUID, number int, userID uuid.UUID, viewer Viewer) (string, error) {
+	var resource models.Resource
+	if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+		return "", ErrResourceNotFound
+	}
+
+	if number == resource.CurrentVersion {
+		return s.download(&resource, resource.S3Key, userID, viewer)
+	}
+
+	version, err := getVersion(resourceID, number)
+	if err != nil {
+		return "", err
+	}
+
+	// The resource's scan status only vouches for versions that were current
+	if err := checkFileScan(version.S3Key); err != nil {
+		return "", err
+	}
+
+	return s.download(&resource, version.S3Key, userID, viewer)
+}
+
+func getVersion(resourceID uuid.UUID, number int) (*models.ResourceVersion, error) {
+	var version models.ResourceVersion
+	if err := database.DB.Where("resource_id = ? AND number = ?", resourceID, number).First(&version).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVersionNotFound
+		}
+		return nil, fmt.Errorf("failed to get version: %w", err)
+	}
+	return &version, nil
+}
+
+// setCurrentVersion copies a version's file onto the resource
+func setCurrentVersion(tx *gorm.DB, resource *models.Resource, version *models.ResourceVersion) error {
+	updates := map[string]interface{}{
+		"file_name":       version.FileName,
+		"file_size":       version.FileSize,
+		"file_type":       version.FileType,
+		"s3_key":          version.S3Key,
+		"content_hash":    version.ContentHash,
+		"current_version": version.Number,
+	}
+	if err := tx.Model(resource).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to set current version: %w", err)
+	}
+	return nil
+}
+
+// notifyBookmarkers tells everyone who bookmarked a resource that it was updated.
+// Failures are logged so they never block uploading.
+func (s *ResourceService) notifyBookmarkers(resource *models.Resource, version *models.ResourceVersion) {
+	var userIDs []uuid.UUID
+	if err := database.DB.Model(&models.Bookmark{}).
+		Where("resource_id = ?", resource.ID).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		fmt.Printf("Warning: failed to list bookmarkers: %v\n", err)
+		return
+	}
+
+	message := fmt.Sprintf("\"%s\" was updated to version %d", resource.Title, version.Number)
+	if version.ChangeNote != "" {
+		message = fmt.Sprintf("%s: %s", message, version.ChangeNote)
+	}
+
+	for _, userID := range userIDs {
+		if _, err := s.notificationService.Notify(NotificationEvent{
+			UserID:     userID,
+			ActorID:    &resource.UserID,
+			Type:       models.NotificationTypeResourceUpdated,
+			Message:    message,
+			EntityType: "resource",
+			EntityID:   &resource.ID,
+		}); err != nil {
+			fmt.Printf("Warning: failed to send resource update notification: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/campus-share/backend/internal/config"
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/scanner"
+	"github.com/campus-share/backend/internal/storage"
+)
+
+var (
+	ErrScanPending     = errors.New("file has not passed the malware scan yet")
+	ErrFileQuarantined = errors.New("file is quarantined")
+)
+
+// scanQueueSize caps how many resources can wait to be scanned. Resources that
+// do not fit stay pending until ScanPending picks them up.
+const scanQueueSize = 1000
+
+// scanAttempts is how often a resource is rescanned when it changes while
+// being scanned, before it is left pending for ScanPending
+const scanAttempts = 3
+
+// ScanService scans the files of uploaded resources for malware in the
+// background. Infected files are moved to quarantine, their resource is hidden
+// and the uploader is notified. Each stored file is scanned once; the resource
+// is clean when all of its files are.
+type ScanService struct {
+	scanner             scanner.Scanner
+	storage             *storage.S3Storage
+	notificationService *NotificationService
+	timeout             time.Duration
+
+	queue   chan uuid.UUID
+	stop    chan struct{}
+	stopped sync.WaitGroup
+}
+
+// NewScanService creates a scan service. Call Start to scan queued resources
+// in the background.
+func NewScanService(fileScanner scanner.Scanner, s3Storage *storage.S3Storage, cfg *config.ScannerConfig) *ScanService {
+	return &ScanService{
+		scanner:             fileScanner,
+		storage:             s3Storage,
+		notificationService: NewNotificationService(),
+		timeout:             cfg.Timeout,
+		queue:               make(chan uuid.UUID, scanQueueSize),
+		stop:                make(chan struct{}),
+	}
+}
+
+// Start starts workers that scan queued resources
+func (s *ScanService) Start(workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		s.stopped.Add(1)
+		go s.run()
+	}
+}
+
+// Close stops the workers once they finish the resource they are scanning.
+// Resources still queued stay pending.
+func (s *ScanService) Close() {
+	close(s.stop)
+	s.stopped.Wait()
+}
+
+func (s *ScanService) run() {
+	defer s.stopped.Done()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case resourceID := <-s.queue:
+			if _, err := s.ScanResource(resourceID); err != nil && err != ErrResourceNotFound {
+				log.Printf("Warning: %v", err)
+			}
+		}
+	}
+}
+
+// Enqueue queues a resource to be scanned
+func (s *ScanService) Enqueue(resourceID uuid.UUID) {
+	select {
+	case s.queue <- resourceID:
+	default:
+		log.Printf("Warning: scan queue is full, resource %s stays pending", resourceID)
+	}
+}
+
+// EnqueuePending queues the resources that are waiting to be scanned or whose
+// scan failed, such as those still queued when the server last stopped, as
+// many as fit in the queue
+func (s *ScanService) EnqueuePending() (int, error) {
+	ids, err := pendingScans(scanQueueSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, id := range ids {
+		s.Enqueue(id)
+	}
+	return len(ids), nil
+}
+
+// ScanPending scans every resource that is waiting to be scanned or whose scan
+// failed, one after the other, and returns how many are now clean
+func (s *ScanService) ScanPending() (int, error) {
+	ids, err := pendingScans(0)
+	if err != nil {
+		return 0, err
+	}
+
+	clean := 0
+	for _, id := range ids {
+		status, err := s.ScanResource(id)
+		if err != nil && err != ErrResourceNotFound {
+			return clean, err
+		}
+		if status == models.ScanStatusClean {
+			clean++
+		}
+	}
+	return clean, nil
+}
+
+// pendingScans lists resources waiting to be scanned, oldest first, up to limit
+// unless it is 0
+func pendingScans(limit int) ([]uuid.UUID, error) {
+	query := database.DB.Model(&models.Resource{}).
+		Where("scan_status IN ?", []models.ScanStatus{models.ScanStatusPending, models.ScanStatusFailed}).
+		Order("created_at")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var ids []uuid.UUID
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list pending scans: %w", err)
+	}
+	return ids, nil
+}
+
+// ScanResource scans the files of a resource that were not scanned before and
+// records the result on the resource
+func (s *ScanService) ScanResource(resourceID uuid.UUID) (models.ScanStatus, error) {
+	for attempt := 0; attempt < scanAttempts; attempt++ {
+		var resource models.Resource
+		if err := database.DB.Where("id = ?", resourceID).First(&resource).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return "", ErrResourceNotFound
+			}
+			return "", fmt.Errorf("failed to get resource: %w", err)
+		}
+
+		files, err := resourceFiles(&resource)
+		if err != nil {
+			return "", err
+		}
+
+		scans := make([]models.FileScan, 0, len(files))
+		for _, file := range files {
+			scan, err := s.scanFile(file.Key)
+			if err != nil {
+				return "", err
+			}
+			scans = append(scans, *scan)
+		}
+
+		// A resource changed while it was scanned may have new files, so it
+		// is only updated if it is still as it was read
+		status, signature := resourceScanStatus(scans)
+		updated, err := s.recordScan(&resource, status, signature)
+		if err != nil {
+			return "", err
+		}
+		if updated {
+			return status, nil
+		}
+	}
+
+	return models.ScanStatusPending, nil
+}
+
+// scanFile returns the scan result of a stored file, scanning it unless it was
+// scanned before. Scanner errors are recorded as a failed scan to be retried.
+func (s *ScanService) scanFile(key string) (*models.FileScan, error) {
+	var scan models.FileScan
+	err := database.DB.Where("s3_key = ?", key).First(&scan).Error
+	if err == nil && scan.Status != models.ScanStatusFailed && scan.Status != models.ScanStatusPending {
+		return &scan, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to get file scan: %w", err)
+	}
+
+	scan = models.FileScan{S3Key: key, ScannedAt: time.Now()}
+	result, err := s.scan(key)
+	switch {
+	case err != nil:
+		scan.Status = models.ScanStatusFailed
+		scan.Error = err.Error()
+	case result.Infected:
+		scan.Status = models.ScanStatusInfected
+		scan.Signature = result.Signature
+
+		// Move the file where presigned download URLs cannot reach it
+		quarantineKey := storage.GenerateQuarantineKey(key)
+		if err := s.storage.MoveFile(key, quarantineKey); err != nil {
+			fmt.Printf("Warning: failed to quarantine file: %v\n", err)
+		} else {
+			scan.QuarantineKey = quarantineKey
+		}
+	default:
+		scan.Status = models.ScanStatusClean
+	}
+
+	// Another worker may have scanned the same file meanwhile; only a failed
+	// or pending result is replaced
+	if err := database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "s3_key"}},
+		UpdateAll: true,
+		Where: clause.Where{Exprs: []clause.Expression{clause.IN{
+			Column: "file_scans.status",
+			Values: []interface{}{models.ScanStatusFailed, models.ScanStatusPending},
+		}}},
+	}).Create(&scan).Error; err != nil {
+		return nil, fmt.Errorf("failed to record file scan: %w", err)
+	}
+
+	if err := database.DB.Where("s3_key = ?", key).First(&scan).Error; err != nil {
+		return nil, fmt.Errorf("failed to get file scan: %w", err)
+	}
+	return &scan, nil
+}
+
+func (s *ScanService) scan(key string) (scanner.Result, error) {
+	ctx := context.Background()
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	body, err := s.storage.GetFile(key)
+	if err != nil {
+		return scanner.Result{}, err
+	}
+	defer body.Close()
+
+	return s.scanner.Scan(ctx, body)
+}
+
+// resourceScanStatus combines the scans of a resource's files: infected if any
+// file is, failed if any could not be scanned, otherwise clean
+func resourceScanStatus(scans []models.FileScan) (models.ScanStatus, string) {
+	status := models.ScanStatusClean
+	for _, scan := range scans {
+		switch scan.Status {
+		case models.ScanStatusInfected:
+			return models.ScanStatusInfected, scan.Signature
+		case models.ScanStatusFailed:
+			status = models.ScanStatusFailed
+		}
+	}
+	return status, ""
+}
+
+// recordScan stores the scan result on the resource unless it changed since it
+// was read. An infected resource is hidden and its uploader notified.
+func (s *ScanService) recordScan(resource *models.Resource, status models.ScanStatus, signature string) (bool, error) {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"scan_status":    status,
+		"scan_signature": signature,
+		"scanned_at":     now,
+	}
+	if status == models.ScanStatusInfected {
+		updates["is_approved"] = false
+	}
+
+	result := database.DB.Model(&models.Resource{}).
+		Where("id = ? AND updated_at = ?", resource.ID, resource.UpdatedAt).
+		Updates(updates)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to record scan: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+
+	if status == models.ScanStatusInfected && resource.ScanStatus != models.ScanStatusInfected {
+		if _, err := s.notificationService.Notify(NotificationEvent{
+			UserID:     resource.UserID,
+			Type:       models.NotificationTypeUploadQuarantined,
+			Message:    fmt.Sprintf("\"%s\" was quarantined because malware was found in it (%s)", resource.Title, signature),
+			EntityType: "resource",
+			EntityID:   &resource.ID,
+		}); err != nil {
+			fmt.Printf("Warning: failed to send quarantine notification: %v\n", err)
+		}
+	}
+
+	return true, nil
+}
+
+// defaultScanService scans new uploads; it is nil in command-line tools
+var defaultScanService *ScanService
+
+// SetScanService sets the service new uploads are queued with
+func SetScanService(s *ScanService) {
+	defaultScanService = s
+}
+
+// queueScan queues a resource whose files changed to be scanned
+func queueScan(resourceID uuid.UUID) {
+	if defaultScanService != nil {
+		defaultScanService.Enqueue(resourceID)
+	}
+}
+
+// markScanPending blocks downloads of a resource whose files changed until
+// they are scanned
+func markScanPending(tx *gorm.DB, resourceID uuid.UUID) error {
+	if err := tx.Model(&models.Resource{}).
+		Where("id = ?", resourceID).
+		Update("scan_status", models.ScanStatusPending).Error; err != nil {
+		return fmt.Errorf("failed to mark resource for scanning: %w", err)
+	}
+	return nil
+}
+
+// checkScan returns an error unless every file of the resource passed the scan
+func checkScan(resource *models.Resource) error {
+	switch resource.ScanStatus {
+	case models.ScanStatusClean:
+		return nil
+	case models.ScanStatusInfected:
+		return ErrFileQuarantined
+	default:
+		return ErrScanPending
+	}
+}
+
+// markFileScanPending records that a stored file waits to be scanned, for files
+// their resource's scan status does not cover, such as versions uploaded
+// without becoming current. A file that was scanned already keeps its result.
+func markFileScanPending(tx *gorm.DB, key string) error {
+	scan := models.FileScan{S3Key: key, Status: models.ScanStatusPending, ScannedAt: time.Now()}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&scan).Error; err != nil {
+		return fmt.Errorf("failed to mark file for scanning: %w", err)
+	}
+	return nil
+}
+
+// checkFileScan returns an error unless a stored file passed the malware scan.
+// Files without a scan result were stored before scanning was added and count
+// as clean.
+func checkFileScan(key string) error {
+	var scan models.FileScan
+	if err := database.DB.Where("s3_key = ?", key).First(&scan).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get file scan: %w", err)
+	}
+
+	switch scan.Status {
+	case models.ScanStatusClean:
+		return nil
+	case models.ScanStatusInfected:
+		return ErrFileQuarantined
+	default:
+		return ErrScanPending
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/campus-share/backend/internal/config"
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/storage"
+)
+
+// retainedFilesSQL lists the stored files that must be kept: those of
+// resources, versions and attachments that are live or were deleted after
+// @cutoff, unless their files were purged already
+const retainedFilesSQL = `
+	SELECT s3_key FROM resources
+	WHERE files_purged_at IS NULL AND (deleted_at IS NULL OR deleted_at >= @cutoff)
+	UNION
+	SELECT v.s3_key FROM resource_versions v
+	JOIN resources r ON r.id = v.resource_id
+	WHERE r.files_purged_at IS NULL AND (r.deleted_at IS NULL OR r.deleted_at >= @cutoff)
+	UNION
+	SELECT f.s3_key FROM resource_files f
+	JOIN resources r ON r.id = f.resource_id
+	WHERE r.files_purged_at IS NULL AND (r.deleted_at IS NULL OR r.deleted_at >= @cutoff)`
+
+// StorageGCService deletes stored files nothing uses any more: files of
+// resources deleted longer ago than the retention period, and orphans such as
+// uploads whose resource was never created or files a failed delete left behind
+type StorageGCService struct {
+	storage *storage.S3Storage
+	config  *config.StorageConfig
+}
+
+// NewStorageGCService creates a new storage garbage collector
+func NewStorageGCService(s3Storage *storage.S3Storage, cfg *config.StorageConfig) *StorageGCService {
+	return &StorageGCService{
+		storage: s3Storage,
+		config:  cfg,
+	}
+}
+
+// GCFile is a stored file the garbage collector found unused
+type GCFile struct {
+	Key     string
+	Size    int64
+	Expired bool // Belonged to a resource deleted longer ago than the retention period
+}
+
+// GCReport is what a garbage collection run found and did
+type GCReport struct {
+	Scanned         int      // Files listed from storage
+	PurgedResources int      // Deleted resources whose retention period is over
+	Unused          []GCFile // Files nothing uses any more
+	Deleted         int
+	Failed          int
+}
+
+// Collect finds stored files nothing uses any more and, if remove is set,
+// deletes them. Files younger than the grace period are left alone, since
+// their resource may still be being created.
+func (s *StorageGCService) Collect(remove bool) (*GCReport, error) {
+	now := time.Now()
+	cutoff := now.Add(-s.config.Retention)
+	report := &GCReport{}
+
+	var expired []models.Resource
+	if err := database.DB.Unscoped().
+		Where("deleted_at < ? AND files_purged_at IS NULL", cutoff).
+		Find(&expired).Error; err != nil {
+		return nil, fmt.Errorf("failed to list deleted resources: %w", err)
+	}
+	report.PurgedResources = len(expired)
+
+	// Content hash of each file of an expired resource
+	expiredFiles := make(map[string]string)
+	expiredIDs := make([]uuid.UUID, 0, len(expired))
+	for i := range expired {
+		files, err := resourceFiles(&expired[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			expiredFiles[file.Key] = file.ContentHash
+		}
+		expiredIDs = append(expiredIDs, expired[i].ID)
+	}
+
+	// From now on the files of expired resources no longer count as used,
+	// so uploads stop sharing them
+	if remove && len(expiredIDs) > 0 {
+		if err := database.DB.Unscoped().Model(&models.Resource{}).
+			Where("id IN ?", expiredIDs).
+			Update("files_purged_at", now).Error; err != nil {
+			return nil, fmt.Errorf("failed to purge deleted resources: %w", err)
+		}
+	}
+
+	var keys []string
+	if err := database.DB.Raw(retainedFilesSQL, map[string]interface{}{"cutoff": cutoff}).
+		Scan(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list stored files: %w", err)
+	}
+	retained := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		retained[key] = true
+	}
+
+	graceCutoff := now.Add(-s.config.GracePeriod)
+	err := s.storage.ListFiles(storage.ResourcePrefix, func(file storage.StoredFile) error {
+		report.Scanned++
+		if retained[file.Key] || file.LastModified.After(graceCutoff) {
+			return nil
+		}
+
+		if remove {
+			// An upload may have started sharing the file since the keys were read
+			users, err := fileUsers(file.Key, expiredFiles[file.Key])
+			if err != nil {
+				return err
+			}
+			if users > 0 {
+				return nil
+			}
+		}
+
+		_, isExpired := expiredFiles[file.Key]
+		report.Unused = append(report.Unused, GCFile{
+			Key:     file.Key,
+			Size:    file.Size,
+			Expired: isExpired,
+		})
+		if !remove {
+			return nil
+		}
+
+		if err := s.storage.DeleteFile(file.Key); err != nil {
+			// Left for the next run
+			fmt.Printf("Warning: %v\n", err)
+			report.Failed++
+			return nil
+		}
+		report.Deleted++
+
+		if err := database.DB.Where("s3_key = ?", file.Key).Delete(&models.FileScan{}).Error; err != nil {
+			fmt.Printf("Warning: failed to delete file scan: %v\n", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
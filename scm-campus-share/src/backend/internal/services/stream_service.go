@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/campus-share/backend/internal/database"
+	"github.com/campus-share/backend/internal/models"
+	"github.com/campus-share/backend/internal/realtime"
+)
+
+var (
+	ErrInvalidStreamTopic  = errors.New("invalid stream topic")
+	ErrTooManyStreamTopics = errors.New("too many stream topics")
+	ErrNoStreamTopics      = errors.New("at least one stream topic is required")
+)
+
+// MaxStreamTopics limits how many topics a single event stream may follow
+const MaxStreamTopics = 20
+
+// StreamService decides which real-time topics a user may subscribe to
+type StreamService struct{}
+
+// NewStreamService creates a new stream service
+func NewStreamService() *StreamService {
+	return &StreamService{}
+}
+
+// ResolveTopics validates the topics a client asked for and maps them to hub topics.
+// Accepted forms are "notifications" for the user's own inbox,
+// "forum_topic:<id>" for replies in a topic and "resource:<id>" for comments on a resource.
+func (s *StreamService) ResolveTopics(userID uuid.UUID, isModerator bool, requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return nil, ErrNoStreamTopics
+	}
+	if len(requested) > MaxStreamTopics {
+		return nil, ErrTooManyStreamTopics
+	}
+
+	seen := make(map[string]bool, len(requested))
+	topics := make([]string, 0, len(requested))
+
+	for _, name := range requested {
+		topic, err := s.resolveTopic(userID, isModerator, strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		if !seen[topic] {
+			seen[topic] = true
+			topics = append(topics, topic)
+		}
+	}
+
+	return topics, nil
+}
+
+func (s *StreamService) resolveTopic(userID uuid.UUID, isModerator bool, name string) (string, error) {
+	if name == realtime.TopicNotifications {
+		return realtime.UserTopic(userID), nil
+	}
+
+	kind, rawID, found := strings.Cut(name, ":")
+	if !found {
+		return "", ErrInvalidStreamTopic
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil {
+		return "", ErrInvalidStreamTopic
+	}
+
+	switch kind {
+	case "forum_topic":
+		var topic models.ForumTopic
+		if err := database.DB.Where("id = ?", id).First(&topic).Error; err != nil {
+			return "", ErrTopicNotFound
+		}
+		// Hidden topics are only visible to moderators
+		if topic.IsDeleted || (!topic.IsApproved && !isModerator) {
+			return "", ErrTopicNotFound
+		}
+		return realtime.ForumTopic(id), nil
+
+	case "resource":
+		var resource models.Resource
+		if err := database.DB.Where("id = ?", id).First(&resource).Error; err != nil {
+			return "", ErrResourceNotFound
+		}
+		// Owners and moderators may follow any resource, everyone else only
+		// those they could list
+		if resource.UserID != userID && !isModerator {
+			var visible int64
+			if err := database.DB.Model(&models.Resource{}).
+				Where("id = ? AND is_approved = ?", id, true).
+				Scopes(visibleResources(&userID)).
+				Count(&visible).Error; err != nil {
+				return "", fmt.Errorf("failed to get resource: %w", err)
+			}
+			if visible == 0 {
+				return "", ErrResourceNotFound
+			}
+		}
+		return realtime.ResourceTopic(id), nil
+	}
+
+	return "", ErrInvalidStreamTopic
+}
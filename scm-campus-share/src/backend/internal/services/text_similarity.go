@@ -0,0 +1,127 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// stopWords are common words that say nothing about what a resource is about
+var stopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true, "this": true,
+	"that": true, "are": true, "was": true, "were": true, "has": true, "have": true,
+	"into": true, "about": true, "your": true, "you": true, "our": true, "its": true,
+	"all": true, "any": true, "can": true, "not": true, "but": true, "also": true,
+	"how": true, "what": true, "which": true, "who": true, "why": true, "when": true,
+}
+
+// tokenize splits text into lowercase words, dropping short words and stop words
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := fields[:0]
+	for _, field := range fields {
+		if len([]rune(field)) < 3 || stopWords[field] {
+			continue
+		}
+		tokens = append(tokens, field)
+	}
+	return tokens
+}
+
+// keywords returns up to n distinct tokens of the text, longest first
+func keywords(text string, n int) []string {
+	seen := make(map[string]bool)
+	var words []string
+	for _, token := range tokenize(text) {
+		if !seen[token] {
+			seen[token] = true
+			words = append(words, token)
+		}
+	}
+
+	sort.SliceStable(words, func(i, j int) bool {
+		return len(words[i]) > len(words[j])
+	})
+	if len(words) > n {
+		words = words[:n]
+	}
+	return words
+}
+
+// tfidfIndex holds TF-IDF vectors of a set of documents, with document
+// frequencies taken from the same set
+type tfidfIndex struct {
+	vectors map[uuid.UUID]map[string]float64
+}
+
+// newTFIDFIndex builds TF-IDF vectors, normalized to unit length, for each document
+func newTFIDFIndex(documents map[uuid.UUID][]string) *tfidfIndex {
+	documentFrequency := make(map[string]int)
+	termFrequencies := make(map[uuid.UUID]map[string]float64, len(documents))
+	for id, tokens := range documents {
+		tf := make(map[string]float64)
+		for _, token := range tokens {
+			tf[token]++
+		}
+		for token := range tf {
+			documentFrequency[token]++
+		}
+		termFrequencies[id] = tf
+	}
+
+	n := float64(len(documents))
+	index := &tfidfIndex{vectors: make(map[uuid.UUID]map[string]float64, len(documents))}
+	for id, tf := range termFrequencies {
+		vector := make(map[string]float64, len(tf))
+		var norm float64
+		for token, count := range tf {
+			weight := (1 + math.Log(count)) * (math.Log((n+1)/float64(documentFrequency[token]+1)) + 1)
+			vector[token] = weight
+			norm += weight * weight
+		}
+		if norm > 0 {
+			norm = math.Sqrt(norm)
+			for token := range vector {
+				vector[token] /= norm
+			}
+		}
+		index.vectors[id] = vector
+	}
+
+	return index
+}
+
+// cosine returns the cosine similarity of two documents, from 0 to 1
+func (idx *tfidfIndex) cosine(a, b uuid.UUID) float64 {
+	va, vb := idx.vectors[a], idx.vectors[b]
+	if len(va) > len(vb) {
+		va, vb = vb, va
+	}
+
+	var dot float64
+	for token, weight := range va {
+		dot += weight * vb[token]
+	}
+	return dot
+}
+
+// jaccard returns the size of the intersection of two sets over the size of their union
+func jaccard(a, b map[uuid.UUID]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	intersection := 0
+	for id := range a {
+		if b[id] {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"net/url"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -12,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/campus-share/backend/internal/config"
+	"github.com/google/uuid"
 )
 
 // S3Storage implements cloud storage using AWS S3 or S3-compatible services
@@ -87,6 +89,21 @@ func (s *S3Storage) GetPresignedURL(key string, expiration time.Duration) (strin
 	return url, nil
 }
 
+// GetFile opens a file in S3 for reading. The content is streamed from S3 as it
+// is read; the caller must close it.
+func (s *S3Storage) GetFile(key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file from S3: %w", err)
+	}
+
+	return out.Body, nil
+}
+
 // DeleteFile deletes a file from S3
 func (s *S3Storage) DeleteFile(key string) error {
 	_, err := s.client.DeleteObject(&s3.DeleteObjectInput{
@@ -101,6 +118,58 @@ func (s *S3Storage) DeleteFile(key string) error {
 	return nil
 }
 
+// MoveFile moves a file to another key in the same bucket
+func (s *S3Storage) MoveFile(srcKey, dstKey string) error {
+	// The copy source is the bucket and key, URL-encoded
+	source := (&url.URL{Path: s.bucketName + "/" + srcKey}).EscapedPath()
+	_, err := s.client.CopyObject(&s3.CopyObjectInput{
+		Bucket:     aws.String(s.bucketName),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(source),
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to copy file in S3: %w", err)
+	}
+
+	return s.DeleteFile(srcKey)
+}
+
+// StoredFile is an object listed from S3
+type StoredFile struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// ListFiles calls fn with every file whose key starts with prefix, a page of
+// keys at a time, and stops at the first error fn returns
+func (s *S3Storage) ListFiles(prefix string, fn func(file StoredFile) error) error {
+	var fnErr error
+	err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			file := StoredFile{
+				Key:          aws.StringValue(object.Key),
+				Size:         aws.Int64Value(object.Size),
+				LastModified: aws.TimeValue(object.LastModified),
+			}
+			if fnErr = fn(file); fnErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to list files in S3: %w", err)
+	}
+
+	return fnErr
+}
+
 // FileExists checks if a file exists in S3
 func (s *S3Storage) FileExists(key string) (bool, error) {
 	_, err := s.client.HeadObject(&s3.HeadObjectInput{
@@ -121,7 +190,27 @@ func (s *S3Storage) FileExists(key string) (bool, error) {
 	return true, nil
 }
 
+// ResourcePrefix is the start of the key of every file uploaded for a resource
+const ResourcePrefix = "resources/"
+
 // GenerateKey generates a unique S3 key for a file
 func GenerateKey(userID, resourceID, fileName string) string {
 	return fmt.Sprintf("resources/%s/%s/%s", userID, resourceID, fileName)
 }
+
+// GenerateVersionKey generates a unique S3 key for a later version of a resource's
+// file. It does not depend on the version number, which is only known once the
+// version is stored, so concurrent uploads never share a key.
+func GenerateVersionKey(userID, resourceID, fileName string) string {
+	return fmt.Sprintf("resources/%s/%s/versions/%s/%s", userID, resourceID, uuid.New().String(), fileName)
+}
+
+// GenerateAttachmentKey generates a unique S3 key for a file attached to a resource
+func GenerateAttachmentKey(userID, resourceID, fileID, fileName string) string {
+	return fmt.Sprintf("resources/%s/%s/files/%s/%s", userID, resourceID, fileID, fileName)
+}
+
+// GenerateQuarantineKey generates the S3 key an infected file is moved to
+func GenerateQuarantineKey(key string) string {
+	return fmt.Sprintf("quarantine/%s", key)
+}